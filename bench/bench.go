@@ -0,0 +1,283 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-debug/client"
+)
+
+// rssInterval is how often the server's memory is sampled
+const rssInterval = 500 * time.Millisecond
+
+// Call is one entry of the call mix
+type Call struct {
+	Tool   string
+	Weight int
+	Args   map[string]interface{}
+}
+
+// ParseCall parses a call spec of the form tool[:weight][=json-arguments]
+func ParseCall(spec string) (Call, error) {
+	call := Call{Weight: 1, Args: map[string]interface{}{}}
+
+	name := spec
+	if i := strings.IndexByte(spec, '='); i >= 0 {
+		name = spec[:i]
+		if err := json.Unmarshal([]byte(spec[i+1:]), &call.Args); err != nil {
+			return call, fmt.Errorf("invalid arguments in call '%s': %w", spec, err)
+		}
+	}
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		weight, err := strconv.Atoi(name[i+1:])
+		if err != nil || weight < 1 {
+			return call, fmt.Errorf("invalid weight in call '%s'", spec)
+		}
+		call.Weight = weight
+		name = name[:i]
+	}
+	if name == "" {
+		return call, fmt.Errorf("missing tool name in call '%s'", spec)
+	}
+	call.Tool = name
+	return call, nil
+}
+
+// Options configures a benchmark run
+type Options struct {
+	Command     []string      // server command and its arguments
+	Calls       []Call        // the call mix
+	Concurrency int           // calls in flight at most
+	Rate        float64       // calls started per second; 0 runs calls back to back
+	Duration    time.Duration // how long to run, unless Requests is reached first
+	Requests    int           // total calls to make; 0 for no limit
+	Timeout     time.Duration // how long to wait for each call
+}
+
+// sample is the outcome of one call
+type sample struct {
+	call      int
+	latency   time.Duration
+	failed    bool // JSON-RPC error, timeout or lost connection
+	toolError bool // result with isError set
+}
+
+// job is a call to make. In rate mode its latency is measured from when it
+// was scheduled, so time spent queued behind a slow server is counted.
+type job struct {
+	call      int
+	scheduled time.Time
+}
+
+// Run starts the server, drives it with the call mix and reports the results
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	command := opts.Command
+	c := client.NewStdioClient("bench", command[0], command[1:])
+	if err := c.Connect(context.Background()); err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	initCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	if _, err := c.Initialize(initCtx); err != nil {
+		return nil, err
+	}
+	if err := c.SendNotification("notifications/initialized", nil); err != nil {
+		return nil, err
+	}
+
+	tools, err := c.ListTools(initCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	known := make(map[string]bool)
+	for _, tool := range tools {
+		known[tool.Name] = true
+	}
+	for _, call := range opts.Calls {
+		if !known[call.Tool] {
+			return nil, fmt.Errorf("server has no tool '%s'", call.Tool)
+		}
+	}
+
+	memory := newMemorySampler(c.Pid())
+	memory.sample()
+
+	runCtx, stop := context.WithTimeout(ctx, opts.Duration)
+	defer stop()
+
+	jobs := make(chan job)
+	go schedule(runCtx, opts, jobs)
+
+	samples := make(chan sample, opts.Concurrency)
+	var workers sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for j := range jobs {
+				samples <- callTool(ctx, c, opts, j)
+				if c.Exited() {
+					stop()
+				}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(samples)
+	}()
+
+	ticker := time.NewTicker(rssInterval)
+	defer ticker.Stop()
+
+	started := time.Now()
+	var collected []sample
+	for done := false; !done; {
+		select {
+		case s, ok := <-samples:
+			if !ok {
+				done = true
+				break
+			}
+			collected = append(collected, s)
+		case <-ticker.C:
+			memory.sample()
+		}
+	}
+	elapsed := time.Since(started)
+	memory.sample()
+
+	report := newReport(opts, collected, elapsed)
+	report.Memory = memory.stats()
+	if c.Exited() {
+		report.Aborted = fmt.Sprintf("server exited after %d calls", len(collected))
+	} else if ctx.Err() != nil {
+		report.Aborted = "interrupted"
+	}
+	return report, nil
+}
+
+// schedule feeds jobs to the workers until the run ends, either as fast as
+// they take them or at the configured rate
+func schedule(ctx context.Context, opts Options, jobs chan<- job) {
+	defer close(jobs)
+
+	totalWeight := 0
+	for _, call := range opts.Calls {
+		totalWeight += call.Weight
+	}
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	var interval time.Duration
+	if opts.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / opts.Rate)
+	}
+	next := time.Now()
+
+	for n := 0; opts.Requests == 0 || n < opts.Requests; n++ {
+		pick := random.Intn(totalWeight)
+		call := 0
+		for pick >= opts.Calls[call].Weight {
+			pick -= opts.Calls[call].Weight
+			call++
+		}
+
+		j := job{call: call}
+		if interval > 0 {
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+			j.scheduled = next
+			next = next.Add(interval)
+		}
+
+		select {
+		case jobs <- j:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// callTool makes one call and measures it
+func callTool(ctx context.Context, c *client.StdioClient, opts Options, j job) sample {
+	call := opts.Calls[j.call]
+	start := time.Now()
+	if !j.scheduled.IsZero() {
+		start = j.scheduled
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	response, err := c.SendRequest(callCtx, c.NewRequest("tools/call", client.CallToolParams{
+		Name:      call.Tool,
+		Arguments: call.Args,
+	}))
+
+	s := sample{call: j.call, latency: time.Since(start)}
+	switch {
+	case err != nil || response.Error != nil:
+		s.failed = true
+	default:
+		var result struct {
+			IsError bool `json:"isError"`
+		}
+		if json.Unmarshal(response.Result, &result) != nil {
+			s.failed = true
+		}
+		s.toolError = result.IsError
+	}
+	return s
+}
+
+// memorySampler tracks the resident memory of the server process tree
+type memorySampler struct {
+	pid       int
+	available bool
+	start     int64
+	peak      int64
+	last      int64
+}
+
+func newMemorySampler(pid int) *memorySampler {
+	return &memorySampler{pid: pid}
+}
+
+func (m *memorySampler) sample() {
+	rss, ok := treeRSS(m.pid)
+	if !ok {
+		return
+	}
+	if !m.available {
+		m.available = true
+		m.start = rss
+	}
+	if rss > m.peak {
+		m.peak = rss
+	}
+	m.last = rss
+}
+
+func (m *memorySampler) stats() *MemoryStats {
+	if !m.available {
+		return nil
+	}
+	return &MemoryStats{
+		StartKB:  m.start,
+		PeakKB:   m.peak,
+		EndKB:    m.last,
+		GrowthKB: m.last - m.start,
+	}
+}
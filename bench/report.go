@@ -0,0 +1,188 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ToolStats summarizes the calls of one tool, or of all of them. Latencies
+// are in milliseconds.
+type ToolStats struct {
+	Tool       string  `json:"tool"`
+	Calls      int     `json:"calls"`
+	Failed     int     `json:"failed"`
+	ToolErrors int     `json:"toolErrors"`
+	ErrorRate  float64 `json:"errorRate"`
+	Throughput float64 `json:"throughput"`
+	P50        float64 `json:"p50Ms"`
+	P90        float64 `json:"p90Ms"`
+	P99        float64 `json:"p99Ms"`
+	Max        float64 `json:"maxMs"`
+}
+
+// MemoryStats is the resident memory of the server process and its
+// descendants over the run
+type MemoryStats struct {
+	StartKB  int64 `json:"startKB"`
+	PeakKB   int64 `json:"peakKB"`
+	EndKB    int64 `json:"endKB"`
+	GrowthKB int64 `json:"growthKB"`
+}
+
+// Report is the result of a benchmark run
+type Report struct {
+	Command []string     `json:"command"`
+	Load    string       `json:"load"`
+	Seconds float64      `json:"seconds"`
+	Total   ToolStats    `json:"total"`
+	Tools   []ToolStats  `json:"tools"`
+	Memory  *MemoryStats `json:"memory,omitempty"`
+	Aborted string       `json:"aborted,omitempty"`
+}
+
+func newReport(opts Options, samples []sample, elapsed time.Duration) *Report {
+	load := fmt.Sprintf("concurrency %d", opts.Concurrency)
+	if opts.Rate > 0 {
+		load = fmt.Sprintf("rate %g/s, at most %d in flight", opts.Rate, opts.Concurrency)
+	}
+	report := &Report{
+		Command: opts.Command,
+		Load:    load,
+		Seconds: elapsed.Seconds(),
+	}
+
+	byTool := make(map[string][]sample)
+	var names []string
+	for _, s := range samples {
+		name := opts.Calls[s.call].Tool
+		if _, exists := byTool[name]; !exists {
+			names = append(names, name)
+		}
+		byTool[name] = append(byTool[name], s)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		report.Tools = append(report.Tools, summarize(name, byTool[name], elapsed))
+	}
+	report.Total = summarize("all", samples, elapsed)
+	return report
+}
+
+// summarize computes the stats of a set of calls
+func summarize(name string, samples []sample, elapsed time.Duration) ToolStats {
+	stats := ToolStats{Tool: name, Calls: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	latencies := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		latencies[i] = s.latency
+		if s.failed {
+			stats.Failed++
+		}
+		if s.toolError {
+			stats.ToolErrors++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	stats.ErrorRate = float64(stats.Failed+stats.ToolErrors) / float64(len(samples))
+	stats.Throughput = float64(len(samples)) / elapsed.Seconds()
+	stats.P50 = milliseconds(percentile(latencies, 50))
+	stats.P90 = milliseconds(percentile(latencies, 90))
+	stats.P99 = milliseconds(percentile(latencies, 99))
+	stats.Max = milliseconds(latencies[len(latencies)-1])
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// LoadReport reads a report written with WriteJSON, for use as a baseline
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	return &report, nil
+}
+
+// Regressions compares the report with a baseline and describes each tool
+// whose throughput dropped, or whose p50 or p99 latency grew, by more than
+// tolerance (0.2 for 20%)
+func (r *Report) Regressions(baseline *Report, tolerance float64) []string {
+	previous := make(map[string]ToolStats)
+	for _, stats := range append(baseline.Tools, baseline.Total) {
+		previous[stats.Tool] = stats
+	}
+
+	var regressions []string
+	for _, stats := range append(r.Tools, r.Total) {
+		old, exists := previous[stats.Tool]
+		if !exists {
+			continue
+		}
+		if old.Throughput > 0 && stats.Throughput < old.Throughput*(1-tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s: throughput %.1f/s, was %.1f/s", stats.Tool, stats.Throughput, old.Throughput))
+		}
+		if old.P50 > 0 && stats.P50 > old.P50*(1+tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s: p50 %.1fms, was %.1fms", stats.Tool, stats.P50, old.P50))
+		}
+		if old.P99 > 0 && stats.P99 > old.P99*(1+tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s: p99 %.1fms, was %.1fms", stats.Tool, stats.P99, old.P99))
+		}
+	}
+	return regressions
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report for reading in a terminal
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Benchmark of %s\n", strings.Join(r.Command, " "))
+	fmt.Fprintf(w, "%d calls in %.1fs (%s)\n", r.Total.Calls, r.Seconds, r.Load)
+	if r.Aborted != "" {
+		fmt.Fprintf(w, "Stopped early: %s\n", r.Aborted)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%-24s %8s %9s %8s %9s %9s %9s %9s\n", "TOOL", "CALLS", "CALLS/S", "ERRORS", "P50 MS", "P90 MS", "P99 MS", "MAX MS")
+	for _, stats := range append(r.Tools, r.Total) {
+		fmt.Fprintf(w, "%-24s %8d %9.1f %7.1f%% %9.1f %9.1f %9.1f %9.1f\n",
+			stats.Tool, stats.Calls, stats.Throughput, stats.ErrorRate*100, stats.P50, stats.P90, stats.P99, stats.Max)
+	}
+	if r.Total.Failed > 0 || r.Total.ToolErrors > 0 {
+		fmt.Fprintf(w, "\nErrors: %d failed calls (JSON-RPC errors and timeouts), %d results with isError\n", r.Total.Failed, r.Total.ToolErrors)
+	}
+
+	if r.Memory != nil {
+		fmt.Fprintf(w, "\nServer RSS: %.1f MB at start, %.1f MB peak, %.1f MB at end (%+.1f MB)\n",
+			float64(r.Memory.StartKB)/1024, float64(r.Memory.PeakKB)/1024, float64(r.Memory.EndKB)/1024, float64(r.Memory.GrowthKB)/1024)
+	}
+}
@@ -0,0 +1,82 @@
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// treeRSS returns the resident memory in KB of a process and all its
+// descendants, so a proxy is measured together with its child servers.
+// It reads /proc and reports false where that is unavailable.
+func treeRSS(pid int) (int64, bool) {
+	if pid == 0 {
+		return 0, false
+	}
+	total, ok := processRSS(pid)
+	if !ok {
+		return 0, false
+	}
+
+	children := childProcesses()
+	queue := children[pid]
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = append(queue[1:], children[child]...)
+		if rss, ok := processRSS(child); ok {
+			total += rss
+		}
+	}
+	return total, true
+}
+
+// processRSS reads VmRSS from /proc/<pid>/status
+func processRSS(pid int) (int64, bool) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmRSS:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			return kb, err == nil
+		}
+	}
+	return 0, false
+}
+
+// childProcesses maps each process ID to the IDs of its children
+func childProcesses() map[int][]int {
+	children := make(map[int][]int)
+	paths, _ := filepath.Glob("/proc/[0-9]*/stat")
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// The command name in parentheses may contain spaces; fields after it
+		// are state and then the parent ID
+		stat := string(data)
+		end := strings.LastIndexByte(stat, ')')
+		if end < 0 {
+			continue
+		}
+		fields := strings.Fields(stat[end+1:])
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(strings.Fields(stat)[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 == nil && err2 == nil {
+			children[ppid] = append(children[ppid], pid)
+		}
+	}
+	return children
+}
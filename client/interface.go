@@ -28,6 +28,59 @@ type MCPClient interface {
 	
 	// IsConnected returns true if the client is currently connected
 	IsConnected() bool
+	
+	// ServerCapabilities returns the capabilities reported during initialize
+	ServerCapabilities() map[string]interface{}
+	
+	// SetNotificationHandler sets the callback for server notifications
+	SetNotificationHandler(handler NotificationHandler)
+	
+	// ListResources discovers available resources from the server
+	ListResources(ctx context.Context) ([]ResourceInfo, error)
+	
+	// ListResourceTemplates discovers available resource templates from the server
+	ListResourceTemplates(ctx context.Context) ([]ResourceTemplateInfo, error)
+	
+	// ReadResource reads the contents of a resource
+	ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error)
+	
+	// SubscribeResource subscribes to update notifications for a resource
+	SubscribeResource(ctx context.Context, uri string) error
+	
+	// UnsubscribeResource cancels a resource subscription
+	UnsubscribeResource(ctx context.Context, uri string) error
+	
+	// ListPrompts discovers available prompts from the server
+	ListPrompts(ctx context.Context) ([]PromptInfo, error)
+	
+	// GetPrompt renders a prompt with the given arguments
+	GetPrompt(ctx context.Context, name string, args map[string]string) (*GetPromptResult, error)
+	
+	// Complete requests completion values for a prompt or resource template argument
+	Complete(ctx context.Context, params CompleteParams) (*CompleteResult, error)
+	
+	// SetLogLevel sets the minimum level of log notifications the server sends
+	SetLogLevel(ctx context.Context, level string) error
+}
+
+// NotificationHandler receives notifications sent by the server
+type NotificationHandler func(method string, params json.RawMessage)
+
+// HasCapability returns true if the capabilities map advertises the named capability
+func HasCapability(capabilities map[string]interface{}, name string) bool {
+	_, exists := capabilities[name]
+	return exists
+}
+
+// HasSubCapability returns true if a capability object has the named flag set,
+// e.g. HasSubCapability(caps, "resources", "subscribe")
+func HasSubCapability(capabilities map[string]interface{}, name, flag string) bool {
+	capability, ok := capabilities[name].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	enabled, _ := capability[flag].(bool)
+	return enabled
 }
 
 // InitializeResult represents the result of MCP initialize request
@@ -45,15 +98,32 @@ type ServerInfo struct {
 
 // ToolInfo represents information about a tool from the server
 type ToolInfo struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	InputSchema json.RawMessage `json:"inputSchema"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	InputSchema  json.RawMessage  `json:"inputSchema"`
+	OutputSchema json.RawMessage  `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are the behavior hints a server gives for a tool
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+// IsReadOnly returns true if the tool is annotated as not modifying its environment
+func (a *ToolAnnotations) IsReadOnly() bool {
+	return a != nil && a.ReadOnlyHint != nil && *a.ReadOnlyHint
 }
 
 // CallToolResult represents the result of a tool invocation
 type CallToolResult struct {
-	Content []ContentItem `json:"content"`
-	IsError bool          `json:"isError,omitempty"`
+	Content           []ContentItem   `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
 }
 
 // ContentItem represents a piece of content in the tool result
@@ -62,6 +132,112 @@ type ContentItem struct {
 	Text string `json:"text,omitempty"`
 }
 
+// ResourceInfo represents a resource exposed by the server
+type ResourceInfo struct {
+	URI         string          `json:"uri"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	MimeType    string          `json:"mimeType,omitempty"`
+	Size        *int64          `json:"size,omitempty"`
+	Annotations json.RawMessage `json:"annotations,omitempty"`
+}
+
+// ResourceTemplateInfo represents a parameterized resource exposed by the server
+type ResourceTemplateInfo struct {
+	URITemplate string          `json:"uriTemplate"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	MimeType    string          `json:"mimeType,omitempty"`
+	Annotations json.RawMessage `json:"annotations,omitempty"`
+}
+
+// ResourceContents represents the text or binary contents of a resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ReadResourceResult represents the result of reading a resource
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// PromptInfo represents a prompt or prompt template exposed by the server
+type PromptInfo struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument accepted by a prompt template
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// GetPromptResult represents a rendered prompt
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage represents one message of a rendered prompt. Content is kept
+// raw so every content type (text, image, audio, resource) passes through.
+type PromptMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// CompletionRef identifies the prompt or resource template being completed
+type CompletionRef struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// Completion reference types
+const (
+	CompletionRefPrompt   = "ref/prompt"
+	CompletionRefResource = "ref/resource"
+)
+
+// CompletionArgument is the argument being completed and its partial value
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionContext carries previously resolved argument values
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompleteParams represents parameters for completion/complete
+type CompleteParams struct {
+	Ref      CompletionRef      `json:"ref"`
+	Argument CompletionArgument `json:"argument"`
+	Context  *CompletionContext `json:"context,omitempty"`
+}
+
+// CompleteResult represents the completion values returned by the server
+type CompleteResult struct {
+	Completion CompletionValues `json:"completion"`
+}
+
+// CompletionValues holds the suggested values for an argument
+type CompletionValues struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
 // ClientError represents an error from the MCP client
 type ClientError struct {
 	Code    int    `json:"code"`
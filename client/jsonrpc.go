@@ -23,6 +23,21 @@ type JSONRPCResponse struct {
 	ID      int64           `json:"id"`
 }
 
+// JSONRPCNotification represents a JSON-RPC 2.0 notification (a request without ID)
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// incomingMessage is used to classify messages read from the server as
+// responses, notifications or server-initiated requests
+type incomingMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
 // JSONRPCError represents a JSON-RPC 2.0 error
 type JSONRPCError struct {
 	Code    int    `json:"code"`
@@ -49,6 +64,28 @@ type ClientInfo struct {
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	Meta      map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// CursorParams represents parameters for paginated list requests
+type CursorParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ResourceURIParams represents parameters for requests that target a single resource
+type ResourceURIParams struct {
+	URI string `json:"uri"`
+}
+
+// GetPromptParams represents parameters for prompts/get
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// SetLevelParams represents parameters for logging/setLevel
+type SetLevelParams struct {
+	Level string `json:"level"`
 }
 
 // RequestIDGenerator generates unique request IDs
@@ -102,6 +139,89 @@ func NewCallToolRequest(idGen *RequestIDGenerator, toolName string, args map[str
 	}
 }
 
+// NewListResourcesRequest creates a new resources/list request
+func NewListResourcesRequest(idGen *RequestIDGenerator, cursor string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "resources/list",
+		Params:  CursorParams{Cursor: cursor},
+		ID:      idGen.NextID(),
+	}
+}
+
+// NewListResourceTemplatesRequest creates a new resources/templates/list request
+func NewListResourceTemplatesRequest(idGen *RequestIDGenerator, cursor string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "resources/templates/list",
+		Params:  CursorParams{Cursor: cursor},
+		ID:      idGen.NextID(),
+	}
+}
+
+// NewReadResourceRequest creates a new resources/read request
+func NewReadResourceRequest(idGen *RequestIDGenerator, uri string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "resources/read",
+		Params:  ResourceURIParams{URI: uri},
+		ID:      idGen.NextID(),
+	}
+}
+
+// NewSubscribeRequest creates a new resources/subscribe or resources/unsubscribe request
+func NewSubscribeRequest(idGen *RequestIDGenerator, method, uri string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  ResourceURIParams{URI: uri},
+		ID:      idGen.NextID(),
+	}
+}
+
+// NewListPromptsRequest creates a new prompts/list request
+func NewListPromptsRequest(idGen *RequestIDGenerator, cursor string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "prompts/list",
+		Params:  CursorParams{Cursor: cursor},
+		ID:      idGen.NextID(),
+	}
+}
+
+// NewGetPromptRequest creates a new prompts/get request
+func NewGetPromptRequest(idGen *RequestIDGenerator, name string, args map[string]string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "prompts/get",
+		Params: GetPromptParams{
+			Name:      name,
+			Arguments: args,
+		},
+		ID: idGen.NextID(),
+	}
+}
+
+// NewCompleteRequest creates a new completion/complete request
+func NewCompleteRequest(idGen *RequestIDGenerator, params CompleteParams) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "completion/complete",
+		Params:  params,
+		ID:      idGen.NextID(),
+	}
+}
+
+// NewSetLevelRequest creates a new logging/setLevel request
+func NewSetLevelRequest(idGen *RequestIDGenerator, level string) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "logging/setLevel",
+		Params:  SetLevelParams{Level: level},
+		ID:      idGen.NextID(),
+	}
+}
+
 // ParseResponse parses a JSON-RPC response and returns typed result
 func ParseResponse(response *JSONRPCResponse, result interface{}) error {
 	if response.Error != nil {
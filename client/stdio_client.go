@@ -17,12 +17,24 @@ type StdioClient struct {
 	command    string
 	args       []string
 	env        []string
+	stderr     io.Writer
 	
 	cmd      *exec.Cmd
 	stdin    io.WriteCloser
 	stdout   io.ReadCloser
 	reader   *bufio.Reader
 	idGen    *RequestIDGenerator
+	writeMu  sync.Mutex
+	
+	// Responses are matched to requests by ID in readLoop
+	pending      map[int64]chan *JSONRPCResponse
+	pendingMu    sync.Mutex
+	readErr      error
+	done         chan struct{}
+	skippedLines int // output lines that were not JSON-RPC messages
+	
+	capabilities        map[string]interface{}
+	notificationHandler NotificationHandler
 	
 	connected bool
 	mu        sync.Mutex
@@ -43,6 +55,11 @@ func (c *StdioClient) SetEnvironment(env []string) {
 	c.env = env
 }
 
+// SetStderr sets where the server process's stderr goes; it is discarded by default
+func (c *StdioClient) SetStderr(w io.Writer) {
+	c.stderr = w
+}
+
 // Connect establishes connection to the MCP server
 func (c *StdioClient) Connect(ctx context.Context) error {
 	c.mu.Lock()
@@ -57,6 +74,7 @@ func (c *StdioClient) Connect(ctx context.Context) error {
 	if c.env != nil {
 		c.cmd.Env = c.env
 	}
+	c.cmd.Stderr = c.stderr
 	
 	// Create pipes
 	stdin, err := c.cmd.StdinPipe()
@@ -80,10 +98,30 @@ func (c *StdioClient) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to start MCP server: %w", err)
 	}
 	
+	c.pending = make(map[int64]chan *JSONRPCResponse)
+	c.readErr = nil
+	c.skippedLines = 0
+	c.done = make(chan struct{})
+	go c.readLoop(c.reader, c.done)
+	
 	c.connected = true
 	return nil
 }
 
+// SetNotificationHandler sets the callback for notifications sent by the server
+func (c *StdioClient) SetNotificationHandler(handler NotificationHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notificationHandler = handler
+}
+
+// ServerCapabilities returns the capabilities reported by the server during initialize
+func (c *StdioClient) ServerCapabilities() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.capabilities
+}
+
 // Initialize performs MCP protocol handshake
 func (c *StdioClient) Initialize(ctx context.Context) (*InitializeResult, error) {
 	if !c.connected {
@@ -105,6 +143,10 @@ func (c *StdioClient) Initialize(ctx context.Context) (*InitializeResult, error)
 		return nil, fmt.Errorf("failed to parse initialize response: %w", err)
 	}
 	
+	c.mu.Lock()
+	c.capabilities = result.Capabilities
+	c.mu.Unlock()
+	
 	return &result, nil
 }
 
@@ -134,6 +176,15 @@ func (c *StdioClient) ListTools(ctx context.Context) ([]ToolInfo, error) {
 	return result.Tools, nil
 }
 
+// metaKey is the context key of the _meta fields sent with tool calls
+type metaKey struct{}
+
+// WithMeta returns a context whose tool calls carry the given _meta fields,
+// such as trace context for the server
+func WithMeta(ctx context.Context, meta map[string]interface{}) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
 // CallTool invokes a specific tool with arguments
 func (c *StdioClient) CallTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
 	if !c.connected {
@@ -142,6 +193,11 @@ func (c *StdioClient) CallTool(ctx context.Context, name string, args map[string
 	
 	// Create tools/call request
 	request := NewCallToolRequest(c.idGen, name, args)
+	if meta, ok := ctx.Value(metaKey{}).(map[string]interface{}); ok {
+		params := request.Params.(CallToolParams)
+		params.Meta = meta
+		request.Params = params
+	}
 	
 	// Send request and get response
 	response, err := c.sendRequest(ctx, request)
@@ -158,6 +214,200 @@ func (c *StdioClient) CallTool(ctx context.Context, name string, args map[string
 	return &result, nil
 }
 
+// ListResources discovers available resources from the server, following pagination
+func (c *StdioClient) ListResources(ctx context.Context) ([]ResourceInfo, error) {
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+	
+	var resources []ResourceInfo
+	cursor := ""
+	for {
+		response, err := c.sendRequest(ctx, NewListResourcesRequest(c.idGen, cursor))
+		if err != nil {
+			return nil, fmt.Errorf("resources/list request failed: %w", err)
+		}
+		
+		var result struct {
+			Resources  []ResourceInfo `json:"resources"`
+			NextCursor string         `json:"nextCursor,omitempty"`
+		}
+		if err := ParseResponse(response, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse resources/list response: %w", err)
+		}
+		
+		resources = append(resources, result.Resources...)
+		if result.NextCursor == "" {
+			return resources, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// ListResourceTemplates discovers available resource templates from the server, following pagination
+func (c *StdioClient) ListResourceTemplates(ctx context.Context) ([]ResourceTemplateInfo, error) {
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+	
+	var templates []ResourceTemplateInfo
+	cursor := ""
+	for {
+		response, err := c.sendRequest(ctx, NewListResourceTemplatesRequest(c.idGen, cursor))
+		if err != nil {
+			return nil, fmt.Errorf("resources/templates/list request failed: %w", err)
+		}
+		
+		var result struct {
+			ResourceTemplates []ResourceTemplateInfo `json:"resourceTemplates"`
+			NextCursor        string                 `json:"nextCursor,omitempty"`
+		}
+		if err := ParseResponse(response, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse resources/templates/list response: %w", err)
+		}
+		
+		templates = append(templates, result.ResourceTemplates...)
+		if result.NextCursor == "" {
+			return templates, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// ReadResource reads the contents of a resource
+func (c *StdioClient) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+	
+	response, err := c.sendRequest(ctx, NewReadResourceRequest(c.idGen, uri))
+	if err != nil {
+		return nil, fmt.Errorf("resources/read request failed: %w", err)
+	}
+	
+	var result ReadResourceResult
+	if err := ParseResponse(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse resources/read response: %w", err)
+	}
+	
+	return &result, nil
+}
+
+// SubscribeResource subscribes to update notifications for a resource
+func (c *StdioClient) SubscribeResource(ctx context.Context, uri string) error {
+	return c.sendSubscription(ctx, "resources/subscribe", uri)
+}
+
+// UnsubscribeResource cancels a resource subscription
+func (c *StdioClient) UnsubscribeResource(ctx context.Context, uri string) error {
+	return c.sendSubscription(ctx, "resources/unsubscribe", uri)
+}
+
+func (c *StdioClient) sendSubscription(ctx context.Context, method, uri string) error {
+	if !c.connected {
+		return fmt.Errorf("client not connected")
+	}
+	
+	response, err := c.sendRequest(ctx, NewSubscribeRequest(c.idGen, method, uri))
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+	
+	var result struct{}
+	if err := ParseResponse(response, &result); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	
+	return nil
+}
+
+// ListPrompts discovers available prompts from the server, following pagination
+func (c *StdioClient) ListPrompts(ctx context.Context) ([]PromptInfo, error) {
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+	
+	var prompts []PromptInfo
+	cursor := ""
+	for {
+		response, err := c.sendRequest(ctx, NewListPromptsRequest(c.idGen, cursor))
+		if err != nil {
+			return nil, fmt.Errorf("prompts/list request failed: %w", err)
+		}
+		
+		var result struct {
+			Prompts    []PromptInfo `json:"prompts"`
+			NextCursor string       `json:"nextCursor,omitempty"`
+		}
+		if err := ParseResponse(response, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse prompts/list response: %w", err)
+		}
+		
+		prompts = append(prompts, result.Prompts...)
+		if result.NextCursor == "" {
+			return prompts, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// GetPrompt renders a prompt with the given arguments
+func (c *StdioClient) GetPrompt(ctx context.Context, name string, args map[string]string) (*GetPromptResult, error) {
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+	
+	response, err := c.sendRequest(ctx, NewGetPromptRequest(c.idGen, name, args))
+	if err != nil {
+		return nil, fmt.Errorf("prompts/get request failed: %w", err)
+	}
+	
+	var result GetPromptResult
+	if err := ParseResponse(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse prompts/get response: %w", err)
+	}
+	
+	return &result, nil
+}
+
+// Complete requests completion values for a prompt or resource template argument
+func (c *StdioClient) Complete(ctx context.Context, params CompleteParams) (*CompleteResult, error) {
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+	
+	response, err := c.sendRequest(ctx, NewCompleteRequest(c.idGen, params))
+	if err != nil {
+		return nil, fmt.Errorf("completion/complete request failed: %w", err)
+	}
+	
+	var result CompleteResult
+	if err := ParseResponse(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse completion/complete response: %w", err)
+	}
+	
+	return &result, nil
+}
+
+// SetLogLevel sets the minimum level of log notifications the server sends
+func (c *StdioClient) SetLogLevel(ctx context.Context, level string) error {
+	if !c.connected {
+		return fmt.Errorf("client not connected")
+	}
+	
+	response, err := c.sendRequest(ctx, NewSetLevelRequest(c.idGen, level))
+	if err != nil {
+		return fmt.Errorf("logging/setLevel request failed: %w", err)
+	}
+	
+	var result struct{}
+	if err := ParseResponse(response, &result); err != nil {
+		return fmt.Errorf("failed to parse logging/setLevel response: %w", err)
+	}
+	
+	return nil
+}
+
 // Close terminates the connection
 func (c *StdioClient) Close() error {
 	c.mu.Lock()
@@ -203,6 +453,88 @@ func (c *StdioClient) Close() error {
 	return nil
 }
 
+// Shutdown closes the server's input and waits up to timeout for the process
+// to exit on its own, as the stdio transport expects, killing it otherwise.
+// Returns true and the process's exit error if it exited by itself.
+func (c *StdioClient) Shutdown(timeout time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	if !c.connected {
+		return true, nil
+	}
+	c.connected = false
+	c.stdin.Close()
+	
+	exited := make(chan error, 1)
+	go func() {
+		exited <- c.cmd.Wait()
+	}()
+	
+	select {
+	case err := <-exited:
+		return true, err
+	case <-time.After(timeout):
+		c.cmd.Process.Kill()
+		<-exited
+		return false, nil
+	}
+}
+
+// NewRequest creates a request with the next request ID, for sending with
+// SendRequest
+func (c *StdioClient) NewRequest(method string, params interface{}) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      c.idGen.NextID(),
+	}
+}
+
+// SendRequest sends any request and returns the raw response, including
+// JSON-RPC errors. Used by tools that exercise the protocol directly.
+func (c *StdioClient) SendRequest(ctx context.Context, request *JSONRPCRequest) (*JSONRPCResponse, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+	return c.sendRequest(ctx, request)
+}
+
+// SendNotification sends any notification
+func (c *StdioClient) SendNotification(method string, params interface{}) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+	return c.sendNotification(method, params)
+}
+
+// Exited returns true once the server's output has ended, which happens
+// when the process exits
+func (c *StdioClient) Exited() bool {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	return c.readErr != nil
+}
+
+// Pid returns the process ID of the server, or 0 if it has not been started
+func (c *StdioClient) Pid() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cmd == nil || c.cmd.Process == nil {
+		return 0
+	}
+	return c.cmd.Process.Pid
+}
+
+// SkippedLines returns the number of output lines that were not JSON-RPC
+// messages, such as debug output written to stdout by mistake
+func (c *StdioClient) SkippedLines() int {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	return c.skippedLines
+}
+
 // ServerName returns the configured name of this server
 func (c *StdioClient) ServerName() string {
 	return c.serverName
@@ -227,47 +559,148 @@ func (c *StdioClient) sendRequest(ctx context.Context, request *JSONRPCRequest)
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 	
+	// Register for the response before sending so it can't be missed
+	responseChan := make(chan *JSONRPCResponse, 1)
+	c.pendingMu.Lock()
+	if c.readErr != nil {
+		err := c.readErr
+		c.pendingMu.Unlock()
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	c.pending[request.ID] = responseChan
+	done := c.done
+	c.pendingMu.Unlock()
+	
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, request.ID)
+		c.pendingMu.Unlock()
+	}()
+	
 	// Send request
-	requestLine := append(requestBytes, '\n')
-	if _, err := c.stdin.Write(requestLine); err != nil {
+	if err := c.writeMessage(requestBytes); err != nil {
 		return nil, fmt.Errorf("failed to write request: %w", err)
 	}
 	
-	// Read response with timeout
-	responseChan := make(chan *JSONRPCResponse, 1)
-	errorChan := make(chan error, 1)
+	// Wait for response or timeout
+	select {
+	case response := <-responseChan:
+		return response, nil
+	case <-done:
+		c.pendingMu.Lock()
+		err := c.readErr
+		c.pendingMu.Unlock()
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("request timeout: %w", ctx.Err())
+	}
+}
+
+// sendNotification sends a JSON-RPC notification, which has no response
+func (c *StdioClient) sendNotification(method string, params interface{}) error {
+	notification := JSONRPCNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
 	
-	go func() {
-		// Read response line
-		responseLine, err := c.reader.ReadBytes('\n')
+	notificationBytes, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	
+	return c.writeMessage(notificationBytes)
+}
+
+// writeMessage writes a single newline-delimited message to the server
+func (c *StdioClient) writeMessage(message []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	
+	_, err := c.stdin.Write(append(message, '\n'))
+	return err
+}
+
+// readLoop reads messages from the server until the stream ends, delivering
+// responses to waiting requests and notifications to the notification handler
+func (c *StdioClient) readLoop(reader *bufio.Reader, done chan struct{}) {
+	for {
+		line, err := reader.ReadBytes('\n')
 		if err != nil {
-			errorChan <- fmt.Errorf("failed to read response: %w", err)
+			c.pendingMu.Lock()
+			c.readErr = err
+			c.pendingMu.Unlock()
+			close(done)
 			return
 		}
 		
-		// Parse response
-		var response JSONRPCResponse
-		if err := json.Unmarshal(responseLine, &response); err != nil {
-			errorChan <- fmt.Errorf("failed to unmarshal response: %w", err)
-			return
+		var message incomingMessage
+		if err := json.Unmarshal(line, &message); err != nil {
+			// Not JSON-RPC (e.g. stray debug output); skip it
+			c.pendingMu.Lock()
+			c.skippedLines++
+			c.pendingMu.Unlock()
+			continue
 		}
 		
-		// Verify response ID matches request ID
-		if response.ID != request.ID {
-			errorChan <- fmt.Errorf("response ID %d does not match request ID %d", response.ID, request.ID)
-			return
+		switch {
+		case message.Method != "" && message.ID == nil:
+			c.handleNotification(message.Method, message.Params)
+		case message.Method != "":
+			c.handleServerRequest(message)
+		default:
+			c.deliverResponse(line)
 		}
-		
+	}
+}
+
+// deliverResponse hands a response to the request waiting for its ID
+func (c *StdioClient) deliverResponse(line []byte) {
+	var response JSONRPCResponse
+	if err := json.Unmarshal(line, &response); err != nil {
+		return
+	}
+	
+	c.pendingMu.Lock()
+	responseChan, exists := c.pending[response.ID]
+	c.pendingMu.Unlock()
+	
+	if exists {
 		responseChan <- &response
-	}()
+	}
+}
+
+// handleNotification passes a server notification to the registered handler
+func (c *StdioClient) handleNotification(method string, params json.RawMessage) {
+	c.mu.Lock()
+	handler := c.notificationHandler
+	c.mu.Unlock()
 	
-	// Wait for response or timeout
-	select {
-	case response := <-responseChan:
-		return response, nil
-	case err := <-errorChan:
-		return nil, err
-	case <-ctx.Done():
-		return nil, fmt.Errorf("request timeout: %w", ctx.Err())
+	if handler != nil {
+		handler(method, params)
 	}
-}
\ No newline at end of file
+}
+
+// handleServerRequest answers requests initiated by the server. Only ping is
+// supported; the proxy does not offer sampling or roots to child servers.
+func (c *StdioClient) handleServerRequest(message incomingMessage) {
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      message.ID,
+	}
+	
+	if message.Method == "ping" {
+		response["result"] = map[string]interface{}{}
+	} else {
+		response["error"] = JSONRPCError{
+			Code:    -32601,
+			Message: fmt.Sprintf("Method %s not supported by proxy", message.Method),
+		}
+	}
+	
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	c.writeMessage(responseBytes)
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamingConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		naming  NamingConfig
+		wantErr string
+	}{
+		{"defaults", NamingConfig{}, ""},
+		{"double underscore", NamingConfig{Separator: "__"}, ""},
+		{"longest separator", NamingConfig{Separator: strings.Repeat(".", maxSeparatorLength)}, ""},
+		{"separator too long", NamingConfig{Separator: strings.Repeat("_", maxSeparatorLength+1)}, "at most"},
+		{"invalid characters", NamingConfig{Separator: "/"}, "may only contain"},
+		{"maxLength too small", NamingConfig{MaxLength: minNameLength - 1}, "at least"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.naming.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
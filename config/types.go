@@ -1,8 +1,15 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -11,6 +18,280 @@ import (
 type ProxyConfig struct {
 	Servers []ServerConfig `yaml:"servers"`
 	Proxy   ProxySettings  `yaml:"proxy"`
+	Naming  NamingConfig   `yaml:"naming,omitempty"`
+	
+	VirtualTools []VirtualToolConfig `yaml:"virtualTools,omitempty"`
+	Redaction    RedactionConfig     `yaml:"redaction,omitempty"`
+	Cache        CacheConfig         `yaml:"cache,omitempty"`
+	Validation   ValidationConfig    `yaml:"validation,omitempty"`
+	Metrics      MetricsConfig       `yaml:"metrics,omitempty"`
+	Tracing      TracingConfig       `yaml:"tracing,omitempty"`
+	Logging      LoggingConfig       `yaml:"logging,omitempty"`
+	Dashboard    DashboardConfig     `yaml:"dashboard,omitempty"`
+}
+
+// Log formats of the proxy's own log
+const (
+	LogFormatLogfmt = "logfmt" // key=value pairs (default)
+	LogFormatJSON   = "json"   // one JSON object per line
+)
+
+// ProxyLogLevels lists the levels of the proxy's own log from least to most
+// severe. They are separate from the MCP levels servers log with.
+var ProxyLogLevels = []string{"debug", "info", "warn", "error"}
+
+// LoggingConfig controls the proxy's own log. Levels can be set per component
+// and per server; a server's level takes precedence over its component's.
+type LoggingConfig struct {
+	Format     string            `yaml:"format,omitempty"`     // logfmt (default) or json
+	Level      string            `yaml:"level,omitempty"`      // default level, info unless MCP_DEBUG is set
+	Components map[string]string `yaml:"components,omitempty"` // component -> level
+	Servers    map[string]string `yaml:"servers,omitempty"`    // server name -> level
+	MaxBytes   int               `yaml:"maxBytes,omitempty"`   // rotate log files at this size; 0 never rotates
+	MaxFiles   int               `yaml:"maxFiles,omitempty"`   // rotated files kept, default 3
+}
+
+// DefaultLogMaxFiles is the number of rotated log files kept by default
+const DefaultLogMaxFiles = 3
+
+// GetMaxFiles returns the number of rotated log files kept, with default
+func (l LoggingConfig) GetMaxFiles() int {
+	if l.MaxFiles <= 0 {
+		return DefaultLogMaxFiles
+	}
+	return l.MaxFiles
+}
+
+// IsValidProxyLogLevel returns true if level is a level of the proxy's own log
+func IsValidProxyLogLevel(level string) bool {
+	for _, known := range ProxyLogLevels {
+		if level == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (l LoggingConfig) validate() error {
+	switch l.Format {
+	case "", LogFormatLogfmt, LogFormatJSON:
+	default:
+		return fmt.Errorf("invalid format '%s' (use logfmt or json)", l.Format)
+	}
+	if l.Level != "" && !IsValidProxyLogLevel(l.Level) {
+		return fmt.Errorf("invalid level '%s'", l.Level)
+	}
+	for component, level := range l.Components {
+		if !IsValidProxyLogLevel(level) {
+			return fmt.Errorf("invalid level '%s' for component %s", level, component)
+		}
+	}
+	for server, level := range l.Servers {
+		if !IsValidProxyLogLevel(level) {
+			return fmt.Errorf("invalid level '%s' for server %s", level, server)
+		}
+	}
+	if l.MaxBytes < 0 || l.MaxFiles < 0 {
+		return fmt.Errorf("maxBytes and maxFiles must not be negative")
+	}
+	return nil
+}
+
+// TracingConfig enables OpenTelemetry tracing of tool calls. Spans are
+// exported to an OTLP endpoint, a file, or both.
+type TracingConfig struct {
+	Endpoint    string `yaml:"endpoint,omitempty"`    // OTLP/HTTP collector, e.g. "http://localhost:4318"
+	File        string `yaml:"file,omitempty"`        // OTLP JSON, one export request per line
+	ServiceName string `yaml:"serviceName,omitempty"` // default "mcp-debug"
+}
+
+// Enabled reports whether spans are exported anywhere
+func (t TracingConfig) Enabled() bool {
+	return t.Endpoint != "" || t.File != ""
+}
+
+// GetServiceName returns the service name reported with spans
+func (t TracingConfig) GetServiceName() string {
+	if t.ServiceName == "" {
+		return "mcp-debug"
+	}
+	return t.ServiceName
+}
+
+// MetricsConfig enables the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Listen string `yaml:"listen,omitempty"` // address serving /metrics, e.g. "127.0.0.1:9464"; off if empty
+}
+
+// DashboardConfig enables the live web dashboard
+type DashboardConfig struct {
+	Listen string `yaml:"listen,omitempty"` // loopback address serving the UI, e.g. "127.0.0.1:8080"; off if empty
+}
+
+// Schema validation modes
+const (
+	ValidationOff     = "off"     // no validation (default)
+	ValidationWarn    = "warn"    // log and record violations, forward anyway
+	ValidationEnforce = "enforce" // reject calls that violate the schema
+)
+
+// ValidationConfig selects how tool calls are checked against tool schemas
+type ValidationConfig struct {
+	Input  string `yaml:"input,omitempty"`  // arguments against inputSchema
+	Output string `yaml:"output,omitempty"` // structuredContent against outputSchema
+}
+
+func (v ValidationConfig) validate() error {
+	for name, mode := range map[string]string{"input": v.Input, "output": v.Output} {
+		switch mode {
+		case "", ValidationOff, ValidationWarn, ValidationEnforce:
+		default:
+			return fmt.Errorf("invalid %s validation mode '%s' (use off, warn or enforce)", name, mode)
+		}
+	}
+	return nil
+}
+
+// InputValidationFor returns the input validation mode of a server: its own
+// setting, else the proxy-wide one, else off
+func (c *ProxyConfig) InputValidationFor(server *ServerConfig) string {
+	if server.Validation != nil && server.Validation.Input != "" {
+		return server.Validation.Input
+	}
+	if c.Validation.Input != "" {
+		return c.Validation.Input
+	}
+	return ValidationOff
+}
+
+// OutputValidationFor returns the output validation mode of a server: its
+// own setting, else the proxy-wide one, else off
+func (c *ProxyConfig) OutputValidationFor(server *ServerConfig) string {
+	if server.Validation != nil && server.Validation.Output != "" {
+		return server.Validation.Output
+	}
+	if c.Validation.Output != "" {
+		return c.Validation.Output
+	}
+	return ValidationOff
+}
+
+// Response cache defaults
+const (
+	DefaultCacheTTL        = 5 * time.Minute
+	DefaultCacheMaxEntries = 1000
+	DefaultCacheMaxBytes   = 10 << 20
+)
+
+// CacheConfig controls the proxy's response cache. Results are cached for
+// tools with per-server cache rules and, if ReadOnlyTools is set, for every
+// tool annotated with readOnlyHint.
+type CacheConfig struct {
+	ReadOnlyTools bool   `yaml:"readOnlyTools,omitempty"`
+	TTL           string `yaml:"ttl,omitempty"`
+	MaxEntries    int    `yaml:"maxEntries,omitempty"`
+	MaxBytes      int    `yaml:"maxBytes,omitempty"` // total size of cached result text
+}
+
+// GetTTL returns the default time to live of cached results
+func (c CacheConfig) GetTTL() time.Duration {
+	if c.TTL == "" {
+		return DefaultCacheTTL
+	}
+	duration, err := time.ParseDuration(c.TTL)
+	if err != nil {
+		return DefaultCacheTTL
+	}
+	return duration
+}
+
+// GetMaxEntries returns the maximum number of cached results, with default
+func (c CacheConfig) GetMaxEntries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultCacheMaxEntries
+	}
+	return c.MaxEntries
+}
+
+// GetMaxBytes returns the maximum total size of cached results, with default
+func (c CacheConfig) GetMaxBytes() int {
+	if c.MaxBytes <= 0 {
+		return DefaultCacheMaxBytes
+	}
+	return c.MaxBytes
+}
+
+// CacheTTLFor returns how long results of a server's tool are cached, 0 if
+// they are not. A tool's own rules override those for "*"; without rules,
+// read-only tools are cached if ReadOnlyTools is set.
+func (c *ProxyConfig) CacheTTLFor(server *ServerConfig, toolName string, readOnly bool) time.Duration {
+	rules, hasRules := server.Cache[toolName]
+	if !hasRules {
+		rules, hasRules = server.Cache["*"]
+	}
+	
+	switch {
+	case hasRules && rules.Disabled:
+		return 0
+	case hasRules && rules.TTL != "":
+		if duration, err := time.ParseDuration(rules.TTL); err == nil {
+			return duration
+		}
+		return c.Cache.GetTTL()
+	case hasRules || (readOnly && c.Cache.ReadOnlyTools):
+		return c.Cache.GetTTL()
+	default:
+		return 0
+	}
+}
+
+// RedactionConfig controls how secrets are removed from recordings, the proxy
+// log and server_list output. Built-in detectors and the values of sensitive
+// env and auth settings are always redacted unless Disabled is set.
+type RedactionConfig struct {
+	Disabled bool     `yaml:"disabled,omitempty"`
+	Patterns []string `yaml:"patterns,omitempty"` // additional regular expressions
+	Env      []string `yaml:"env,omitempty"`      // more env variables whose values are secrets
+}
+
+// ManagementToolNames are the tools the dynamic proxy serves itself
+var ManagementToolNames = []string{
+	"server_add", "server_remove", "server_list", "server_disconnect", "server_reconnect",
+	"cache_clear", "chaos_set", "proxy_log_level",
+}
+
+// VirtualToolConfig defines a tool implemented by the proxy as a sequence of
+// calls to proxied tools. Step arguments may reference the virtual tool's
+// inputs and earlier step results with {{input.name}} and {{steps.id.text}}.
+type VirtualToolConfig struct {
+	Name        string             `yaml:"name"`
+	Description string             `yaml:"description,omitempty"`
+	Inputs      []VirtualToolInput `yaml:"inputs,omitempty"`
+	Steps       []VirtualToolStep  `yaml:"steps"`
+	Output      string             `yaml:"output,omitempty"` // result template, defaults to the last step's text
+}
+
+// VirtualToolInput describes one input parameter of a virtual tool
+type VirtualToolInput struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type,omitempty"` // JSON schema type, default "string"
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
+// VirtualToolStep is one proxied tool call of a virtual tool
+type VirtualToolStep struct {
+	ID   string                 `yaml:"id,omitempty"` // defaults to "step<N>", 1-based
+	Tool string                 `yaml:"tool"`         // exposed name of the proxied tool
+	Args map[string]interface{} `yaml:"args,omitempty"`
+}
+
+// StepID returns the ID under which a step's result can be referenced
+func (s VirtualToolStep) StepID(index int) string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return fmt.Sprintf("step%d", index+1)
 }
 
 // ServerConfig represents configuration for a remote MCP server
@@ -24,6 +305,418 @@ type ServerConfig struct {
 	URL       string          `yaml:"url,omitempty"`
 	Auth      *AuthConfig     `yaml:"auth,omitempty"`
 	Timeout   string          `yaml:"timeout,omitempty"`
+	LogLevel  string          `yaml:"logLevel,omitempty"`
+	Rename    map[string]string `yaml:"rename,omitempty"` // original tool name -> exposed name
+	Tools     *ToolFilter     `yaml:"tools,omitempty"`
+	Arguments map[string]ArgumentRules `yaml:"arguments,omitempty"` // tool name or "*" -> rules
+	Transform map[string]TransformRules `yaml:"transform,omitempty"` // tool name or "*" -> rules
+	MaxConcurrent int            `yaml:"maxConcurrent,omitempty"` // 0 means unlimited
+	RateLimit *RateLimitConfig   `yaml:"rateLimit,omitempty"`
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuitBreaker,omitempty"`
+	Cache     map[string]CacheRules `yaml:"cache,omitempty"` // tool name or "*" -> rules
+	Validation *ValidationConfig `yaml:"validation,omitempty"` // overrides the proxy-wide modes
+	Chaos     map[string]ChaosRules `yaml:"chaos,omitempty"` // tool name or "*" -> injected faults
+	LogFile   string          `yaml:"logFile,omitempty"` // also write this server's log entries and stderr here
+}
+
+// CacheRules enable caching of a tool's results. Disabled opts a tool out,
+// e.g. a read-only tool whose results change too often.
+type CacheRules struct {
+	TTL      string `yaml:"ttl,omitempty"` // default: cache.ttl
+	Disabled bool   `yaml:"disabled,omitempty"`
+}
+
+// ChaosRules inject faults into a tool's calls, for testing how clients
+// handle slow and failing servers. Every call gets the added latency and at
+// most one fault, picked with the given probabilities (0 to 1, adding up to
+// at most 1). Forced errors and disconnects replace the call; the other
+// faults let it reach the server and alter the response.
+type ChaosRules struct {
+	Latency        string  `yaml:"latency,omitempty"`        // added before the call is forwarded
+	Jitter         string  `yaml:"jitter,omitempty"`         // random extra latency, up to this much
+	ToolError      float64 `yaml:"toolError,omitempty"`      // result with isError set
+	RPCError       float64 `yaml:"rpcError,omitempty"`       // JSON-RPC internal error instead of a result
+	Disconnect     float64 `yaml:"disconnect,omitempty"`     // call fails as if the connection was lost
+	Drop           float64 `yaml:"drop,omitempty"`           // response discarded, the call times out
+	DropTimeout    string  `yaml:"dropTimeout,omitempty"`    // dropped calls time out after this long, default never
+	Truncate       float64 `yaml:"truncate,omitempty"`       // result text cut short
+	TruncateLength int     `yaml:"truncateLength,omitempty"` // characters kept, default half the text
+}
+
+// GetLatency returns the added latency
+func (r ChaosRules) GetLatency() time.Duration {
+	latency, _ := time.ParseDuration(r.Latency)
+	return latency
+}
+
+// GetJitter returns the maximum random extra latency
+func (r ChaosRules) GetJitter() time.Duration {
+	jitter, _ := time.ParseDuration(r.Jitter)
+	return jitter
+}
+
+// GetDropTimeout returns how long a call with a dropped response waits
+// before it times out, 0 if it never does
+func (r ChaosRules) GetDropTimeout() time.Duration {
+	timeout, _ := time.ParseDuration(r.DropTimeout)
+	return timeout
+}
+
+// Validate checks the durations and probabilities
+func (r ChaosRules) Validate() error {
+	for name, value := range map[string]string{"latency": r.Latency, "jitter": r.Jitter, "dropTimeout": r.DropTimeout} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("invalid %s '%s'", name, value)
+		}
+	}
+	
+	total := 0.0
+	for _, p := range []float64{r.ToolError, r.RPCError, r.Disconnect, r.Drop, r.Truncate} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("fault probabilities must be between 0 and 1")
+		}
+		total += p
+	}
+	if total > 1 {
+		return fmt.Errorf("fault probabilities add up to %g, more than 1", total)
+	}
+	if r.TruncateLength < 0 {
+		return fmt.Errorf("truncateLength must not be negative")
+	}
+	return nil
+}
+
+// Circuit breaker defaults
+const (
+	DefaultFailureThreshold = 5
+	DefaultSuccessThreshold = 1
+	DefaultOpenTimeout      = 30 * time.Second
+)
+
+// CircuitBreakerConfig tunes the circuit breaker in front of a server. After
+// FailureThreshold consecutive failed calls the circuit opens and calls fail
+// immediately; after OpenTimeout calls are let through one at a time as
+// probes, and SuccessThreshold successful probes close the circuit again.
+type CircuitBreakerConfig struct {
+	Disabled         bool   `yaml:"disabled,omitempty"`
+	FailureThreshold int    `yaml:"failureThreshold,omitempty"`
+	SuccessThreshold int    `yaml:"successThreshold,omitempty"`
+	OpenTimeout      string `yaml:"openTimeout,omitempty"`
+}
+
+// GetFailureThreshold returns the failure threshold, with default
+func (c *CircuitBreakerConfig) GetFailureThreshold() int {
+	if c == nil || c.FailureThreshold <= 0 {
+		return DefaultFailureThreshold
+	}
+	return c.FailureThreshold
+}
+
+// GetSuccessThreshold returns the success threshold, with default
+func (c *CircuitBreakerConfig) GetSuccessThreshold() int {
+	if c == nil || c.SuccessThreshold <= 0 {
+		return DefaultSuccessThreshold
+	}
+	return c.SuccessThreshold
+}
+
+// GetOpenTimeout returns how long the circuit stays open, with default
+func (c *CircuitBreakerConfig) GetOpenTimeout() time.Duration {
+	if c == nil || c.OpenTimeout == "" {
+		return DefaultOpenTimeout
+	}
+	duration, err := time.ParseDuration(c.OpenTimeout)
+	if err != nil {
+		return DefaultOpenTimeout
+	}
+	return duration
+}
+
+// RateLimitConfig limits the calls forwarded to a server with a token bucket:
+// RequestsPerSecond tokens are added per second, up to Burst
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	Burst             int     `yaml:"burst,omitempty"` // default: RequestsPerSecond rounded up, at least 1
+}
+
+// GetBurst returns the bucket size, with default
+func (r *RateLimitConfig) GetBurst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return int(math.Max(1, math.Ceil(r.RequestsPerSecond)))
+}
+
+// TransformRules shape the text of a tool's result before it reaches the
+// client. They are applied in field order: redaction, JSON pretty-printing,
+// line extraction and finally truncation.
+type TransformRules struct {
+	Redact     []RedactRule `yaml:"redact,omitempty"`
+	PrettyJSON bool         `yaml:"prettyJSON,omitempty"`
+	Lines      string       `yaml:"lines,omitempty"`     // 1-based inclusive range: "10-20", "-50" or "100-"
+	MaxLength  int          `yaml:"maxLength,omitempty"` // characters, longer text is truncated with a marker
+}
+
+// RedactRule replaces every match of a regular expression
+type RedactRule struct {
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement,omitempty"` // default "[REDACTED]"
+}
+
+// TransformRulesFor returns the transform rules for a tool: the tool's own
+// settings override those for "*", and redaction rules from both apply.
+// Returns nil if there are none.
+func (s *ServerConfig) TransformRulesFor(toolName string) *TransformRules {
+	wildcard, hasWildcard := s.Transform["*"]
+	specific, hasSpecific := s.Transform[toolName]
+	if !hasWildcard && !hasSpecific {
+		return nil
+	}
+	
+	merged := wildcard
+	merged.Redact = append(append([]RedactRule{}, wildcard.Redact...), specific.Redact...)
+	if specific.PrettyJSON {
+		merged.PrettyJSON = true
+	}
+	if specific.Lines != "" {
+		merged.Lines = specific.Lines
+	}
+	if specific.MaxLength != 0 {
+		merged.MaxLength = specific.MaxLength
+	}
+	return &merged
+}
+
+// ParseLineRange parses a 1-based inclusive line range. A missing start
+// means 1 and a missing end (returned as 0) means the last line.
+func ParseLineRange(spec string) (start, end int, err error) {
+	startText, endText, found := strings.Cut(spec, "-")
+	if !found {
+		endText = startText
+	}
+	
+	start = 1
+	if startText != "" {
+		if start, err = strconv.Atoi(startText); err != nil || start < 1 {
+			return 0, 0, fmt.Errorf("invalid line range '%s'", spec)
+		}
+	}
+	if endText != "" {
+		if end, err = strconv.Atoi(endText); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid line range '%s'", spec)
+		}
+	}
+	return start, end, nil
+}
+
+// validate checks the redaction patterns and line range
+func (t TransformRules) validate() error {
+	for _, rule := range t.Redact {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid redact pattern '%s': %w", rule.Pattern, err)
+		}
+	}
+	if t.Lines != "" {
+		if _, _, err := ParseLineRange(t.Lines); err != nil {
+			return err
+		}
+	}
+	if t.MaxLength < 0 {
+		return fmt.Errorf("maxLength must not be negative")
+	}
+	return nil
+}
+
+// ArgumentRules rewrite a tool's arguments before the call is forwarded.
+// Stripped arguments are removed first, then defaults fill in missing
+// arguments and finally injected arguments overwrite whatever was sent.
+type ArgumentRules struct {
+	Inject   map[string]interface{} `yaml:"inject,omitempty"`
+	Defaults map[string]interface{} `yaml:"defaults,omitempty"`
+	Strip    []string               `yaml:"strip,omitempty"`
+}
+
+// ArgumentRulesFor returns the argument rules for a tool, merging the rules
+// for "*" with the tool's own, or nil if there are none
+func (s *ServerConfig) ArgumentRulesFor(toolName string) *ArgumentRules {
+	wildcard, hasWildcard := s.Arguments["*"]
+	specific, hasSpecific := s.Arguments[toolName]
+	if !hasWildcard && !hasSpecific {
+		return nil
+	}
+	
+	merged := &ArgumentRules{
+		Inject:   make(map[string]interface{}),
+		Defaults: make(map[string]interface{}),
+	}
+	for _, rules := range []ArgumentRules{wildcard, specific} {
+		for key, value := range rules.Inject {
+			merged.Inject[key] = value
+		}
+		for key, value := range rules.Defaults {
+			merged.Defaults[key] = value
+		}
+		merged.Strip = append(merged.Strip, rules.Strip...)
+	}
+	return merged
+}
+
+// Apply returns a copy of args with the rules applied. A nil receiver
+// returns args unchanged.
+func (r *ArgumentRules) Apply(args map[string]interface{}) map[string]interface{} {
+	if r == nil {
+		return args
+	}
+	
+	result := make(map[string]interface{}, len(args)+len(r.Inject))
+	for key, value := range args {
+		result[key] = value
+	}
+	for _, key := range r.Strip {
+		delete(result, key)
+	}
+	for key, value := range r.Defaults {
+		if _, exists := result[key]; !exists {
+			result[key] = value
+		}
+	}
+	for key, value := range r.Inject {
+		result[key] = value
+	}
+	return result
+}
+
+// ExposedSchema adapts a tool's input schema to the rules: injected
+// arguments are removed since the client cannot change them, and arguments
+// with defaults are no longer required
+func (r *ArgumentRules) ExposedSchema(schema json.RawMessage) json.RawMessage {
+	if r == nil || len(schema) == 0 || (len(r.Inject) == 0 && len(r.Defaults) == 0) {
+		return schema
+	}
+	
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(schema, &parsed); err != nil {
+		return schema
+	}
+	
+	if properties, ok := parsed["properties"].(map[string]interface{}); ok {
+		for key := range r.Inject {
+			delete(properties, key)
+		}
+	}
+	if required, ok := parsed["required"].([]interface{}); ok {
+		kept := make([]interface{}, 0, len(required))
+		for _, name := range required {
+			key, _ := name.(string)
+			_, injected := r.Inject[key]
+			_, defaulted := r.Defaults[key]
+			if !injected && !defaulted {
+				kept = append(kept, name)
+			}
+		}
+		if len(kept) > 0 {
+			parsed["required"] = kept
+		} else {
+			delete(parsed, "required")
+		}
+	}
+	
+	adapted, err := json.Marshal(parsed)
+	if err != nil {
+		return schema
+	}
+	return adapted
+}
+
+// ToolFilter selects which of a server's tools are registered. Patterns are
+// globs (as in path.Match) matched against the original tool names.
+type ToolFilter struct {
+	Include []string `yaml:"include,omitempty"` // register only matching tools; all if empty
+	Exclude []string `yaml:"exclude,omitempty"` // never register matching tools
+}
+
+// Allows returns true if a tool passes the filter. A nil filter allows everything.
+func (f *ToolFilter) Allows(toolName string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Include) > 0 && !matchesAny(f.Include, toolName) {
+		return false
+	}
+	return !matchesAny(f.Exclude, toolName)
+}
+
+// Validate checks that all patterns are well-formed globs
+func (f *ToolFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// NamingConfig controls how remote tool and prompt names are exposed
+type NamingConfig struct {
+	Separator string `yaml:"separator,omitempty"` // joins prefix and name, default "_"
+	NoPrefix  bool   `yaml:"noPrefix,omitempty"`  // expose original names, for single-server setups
+	MaxLength int    `yaml:"maxLength,omitempty"` // longer names are shortened with a hash, default 64
+}
+
+// Naming defaults
+const (
+	DefaultNameSeparator = "_"
+	DefaultMaxNameLength = 64
+	
+	// NameHashLength is the number of hex characters appended to shortened names
+	NameHashLength = 8
+	
+	// minNameLength leaves room for a shortened name's hash suffix
+	minNameLength = 16
+	
+	// maxSeparatorLength keeps at least one character of a shortened name
+	// before the separator and hash suffix
+	maxSeparatorLength = minNameLength - NameHashLength - 1
+)
+
+// GetNaming returns the naming settings with defaults
+func (c *ProxyConfig) GetNaming() NamingConfig {
+	naming := c.Naming
+	if naming.Separator == "" {
+		naming.Separator = DefaultNameSeparator
+	}
+	if naming.MaxLength == 0 {
+		naming.MaxLength = DefaultMaxNameLength
+	}
+	return naming
+}
+
+// LogLevels lists the MCP logging levels from least to most severe
+var LogLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// IsValidLogLevel returns true if level is a known MCP logging level
+func IsValidLogLevel(level string) bool {
+	for _, known := range LogLevels {
+		if level == known {
+			return true
+		}
+	}
+	return false
 }
 
 // AuthConfig represents authentication configuration
@@ -43,6 +736,70 @@ type ProxySettings struct {
 
 // Validate validates the configuration
 func (c *ProxyConfig) Validate() error {
+	// Validate naming settings, which also apply to dynamically added servers
+	if err := c.Naming.validate(); err != nil {
+		return fmt.Errorf("invalid naming: %w", err)
+	}
+	
+	// Validate virtual tools
+	if err := c.validateVirtualTools(); err != nil {
+		return err
+	}
+	
+	// Validate cache settings
+	if c.Cache.TTL != "" {
+		if _, err := time.ParseDuration(c.Cache.TTL); err != nil {
+			return fmt.Errorf("invalid cache ttl: %w", err)
+		}
+	}
+	if c.Cache.MaxEntries < 0 || c.Cache.MaxBytes < 0 {
+		return fmt.Errorf("cache maxEntries and maxBytes must not be negative")
+	}
+	
+	// Validate schema validation modes
+	if err := c.Validation.validate(); err != nil {
+		return fmt.Errorf("invalid validation: %w", err)
+	}
+	
+	// Validate metrics endpoint
+	if c.Metrics.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Listen); err != nil {
+			return fmt.Errorf("invalid metrics listen address: %w", err)
+		}
+	}
+	
+	// Validate dashboard address. The dashboard has no authentication and
+	// can restart servers, so it must not be reachable from other hosts.
+	if c.Dashboard.Listen != "" {
+		host, _, err := net.SplitHostPort(c.Dashboard.Listen)
+		if err != nil {
+			return fmt.Errorf("invalid dashboard listen address: %w", err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("invalid dashboard listen address '%s': must be a loopback address such as 127.0.0.1", c.Dashboard.Listen)
+		}
+	}
+	
+	// Validate tracing endpoint
+	if c.Tracing.Endpoint != "" {
+		endpoint, err := url.Parse(c.Tracing.Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("invalid tracing endpoint '%s': must be an http or https URL", c.Tracing.Endpoint)
+		}
+	}
+	
+	// Validate logging settings
+	if err := c.Logging.validate(); err != nil {
+		return fmt.Errorf("invalid logging: %w", err)
+	}
+	
+	// Validate redaction patterns
+	for _, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction pattern '%s': %w", pattern, err)
+		}
+	}
+	
 	// Allow empty server lists for dynamic proxies
 	if len(c.Servers) == 0 {
 		return nil
@@ -93,6 +850,82 @@ func (c *ProxyConfig) Validate() error {
 				return fmt.Errorf("server %s: invalid timeout format: %w", server.Name, err)
 			}
 		}
+		
+		// Validate log level if specified
+		if server.LogLevel != "" && !IsValidLogLevel(server.LogLevel) {
+			return fmt.Errorf("server %s: invalid logLevel '%s'", server.Name, server.LogLevel)
+		}
+		
+		// Validate call limits
+		if server.MaxConcurrent < 0 {
+			return fmt.Errorf("server %s: maxConcurrent must not be negative", server.Name)
+		}
+		if server.RateLimit != nil {
+			if server.RateLimit.RequestsPerSecond <= 0 {
+				return fmt.Errorf("server %s: rateLimit.requestsPerSecond must be positive", server.Name)
+			}
+			if server.RateLimit.Burst < 0 {
+				return fmt.Errorf("server %s: rateLimit.burst must not be negative", server.Name)
+			}
+		}
+		
+		// Validate circuit breaker settings
+		if breaker := server.CircuitBreaker; breaker != nil {
+			if breaker.FailureThreshold < 0 || breaker.SuccessThreshold < 0 {
+				return fmt.Errorf("server %s: circuitBreaker thresholds must not be negative", server.Name)
+			}
+			if breaker.OpenTimeout != "" {
+				if _, err := time.ParseDuration(breaker.OpenTimeout); err != nil {
+					return fmt.Errorf("server %s: invalid circuitBreaker.openTimeout: %w", server.Name, err)
+				}
+			}
+		}
+		
+		// Validate schema validation modes
+		if server.Validation != nil {
+			if err := server.Validation.validate(); err != nil {
+				return fmt.Errorf("server %s: %w", server.Name, err)
+			}
+		}
+		
+		// Validate cache rules
+		for toolName, rules := range server.Cache {
+			if rules.TTL != "" {
+				if _, err := time.ParseDuration(rules.TTL); err != nil {
+					return fmt.Errorf("server %s: invalid cache ttl for %s: %w", server.Name, toolName, err)
+				}
+			}
+		}
+		
+		// Validate fault injection rules
+		for toolName, rules := range server.Chaos {
+			if err := rules.Validate(); err != nil {
+				return fmt.Errorf("server %s: chaos for %s: %w", server.Name, toolName, err)
+			}
+		}
+		
+		// Validate tool filter
+		if err := server.Tools.Validate(); err != nil {
+			return fmt.Errorf("server %s: %w", server.Name, err)
+		}
+		
+		// Validate response transforms
+		for toolName, rules := range server.Transform {
+			if err := rules.validate(); err != nil {
+				return fmt.Errorf("server %s: transform for %s: %w", server.Name, toolName, err)
+			}
+		}
+		
+		// Validate tool renames
+		maxLength := c.GetNaming().MaxLength
+		for original, renamed := range server.Rename {
+			if renamed == "" {
+				return fmt.Errorf("server %s: rename of '%s' is empty", server.Name, original)
+			}
+			if len(renamed) > maxLength {
+				return fmt.Errorf("server %s: rename '%s' exceeds maxLength %d", server.Name, renamed, maxLength)
+			}
+		}
 	}
 	
 	// Validate proxy settings
@@ -141,6 +974,87 @@ func (c *ProxyConfig) ExpandEnvVars() {
 	}
 }
 
+// validateVirtualTools checks virtual tool definitions
+func (c *ProxyConfig) validateVirtualTools() error {
+	names := make(map[string]bool)
+	for i, tool := range c.VirtualTools {
+		if tool.Name == "" {
+			return fmt.Errorf("virtual tool %d: name is required", i)
+		}
+		if names[tool.Name] {
+			return fmt.Errorf("duplicate virtual tool name: %s", tool.Name)
+		}
+		if isManagementTool(tool.Name) {
+			return fmt.Errorf("virtual tool %s: name is reserved for a management tool", tool.Name)
+		}
+		names[tool.Name] = true
+		
+		for _, input := range tool.Inputs {
+			if input.Name == "" {
+				return fmt.Errorf("virtual tool %s: input name is required", tool.Name)
+			}
+			switch input.Type {
+			case "", "string", "number", "integer", "boolean", "object", "array":
+			default:
+				return fmt.Errorf("virtual tool %s: input %s has invalid type '%s'", tool.Name, input.Name, input.Type)
+			}
+		}
+		
+		if len(tool.Steps) == 0 {
+			return fmt.Errorf("virtual tool %s: at least one step is required", tool.Name)
+		}
+		stepIDs := make(map[string]bool)
+		for j, step := range tool.Steps {
+			if step.Tool == "" {
+				return fmt.Errorf("virtual tool %s: step %d: tool is required", tool.Name, j+1)
+			}
+			if isManagementTool(step.Tool) || c.isVirtualTool(step.Tool) {
+				return fmt.Errorf("virtual tool %s: step %d: '%s' is not a proxied tool", tool.Name, j+1, step.Tool)
+			}
+			id := step.StepID(j)
+			if stepIDs[id] {
+				return fmt.Errorf("virtual tool %s: duplicate step id: %s", tool.Name, id)
+			}
+			stepIDs[id] = true
+		}
+	}
+	return nil
+}
+
+// isManagementTool reports whether name is one of ManagementToolNames
+func isManagementTool(name string) bool {
+	for _, managementTool := range ManagementToolNames {
+		if name == managementTool {
+			return true
+		}
+	}
+	return false
+}
+
+// isVirtualTool reports whether a virtual tool is named name
+func (c *ProxyConfig) isVirtualTool(name string) bool {
+	for _, tool := range c.VirtualTools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the naming settings
+func (n NamingConfig) validate() error {
+	if n.Separator != "" && strings.Trim(n.Separator, "_-.") != "" {
+		return fmt.Errorf("separator '%s' may only contain '_', '-' and '.'", n.Separator)
+	}
+	if len(n.Separator) > maxSeparatorLength {
+		return fmt.Errorf("separator '%s' must be at most %d characters", n.Separator, maxSeparatorLength)
+	}
+	if n.MaxLength != 0 && n.MaxLength < minNameLength {
+		return fmt.Errorf("maxLength must be at least %d", minNameLength)
+	}
+	return nil
+}
+
 // expandEnvVar expands environment variables in the format ${VAR}
 func expandEnvVar(value string) string {
 	if value == "" {
@@ -0,0 +1,470 @@
+package conform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"mcp-debug/client"
+)
+
+// Spec sections cited by findings, relative to the SpecVersion revision
+const (
+	sectionMessages     = "basic#responses"
+	sectionInit         = "basic/lifecycle#initialization"
+	sectionShutdown     = "basic/lifecycle#shutdown"
+	sectionStdio        = "basic/transports#stdio"
+	sectionPing         = "basic/utilities/ping"
+	sectionCancellation = "basic/utilities/cancellation"
+	sectionPagination   = "server/utilities/pagination"
+	sectionToolsCaps    = "server/tools#capabilities"
+	sectionToolsList    = "server/tools#listing-tools"
+	sectionTool         = "server/tools#tool"
+	sectionToolErrors   = "server/tools#error-handling"
+)
+
+// JSON-RPC error codes the checker expects
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxPages bounds tools/list pagination in case a server never stops
+const maxPages = 100
+
+// Options configures a conformance run
+type Options struct {
+	Command []string      // server command and its arguments
+	Timeout time.Duration // how long to wait for each response
+}
+
+// Checker walks a server through the protocol lifecycle
+type Checker struct {
+	opts         Options
+	client       *client.StdioClient
+	capabilities map[string]interface{}
+	serverInfo   *client.ServerInfo
+	version      string // protocol version the server answered
+	tools        []client.ToolInfo
+	abort        string // why the remaining checks are skipped, if they are
+}
+
+// Run launches the server and checks its behavior at each step of the
+// lifecycle, stopping early if the server fails to initialize or exits
+func Run(ctx context.Context, opts Options) *Report {
+	report := &Report{Command: opts.Command, SpecVersion: SpecVersion}
+	ch := &Checker{
+		opts:   opts,
+		client: client.NewStdioClient("conform", opts.Command[0], opts.Command[1:]),
+	}
+
+	if err := ch.client.Connect(context.Background()); err != nil {
+		report.add(CheckResult{Name: "launch", Findings: []Finding{{
+			Severity: SeverityError, Section: sectionStdio, Message: err.Error(),
+		}}})
+		return report
+	}
+
+	steps := []struct {
+		name string
+		run  func(context.Context, *CheckResult)
+	}{
+		{"initialize", ch.checkInitialize},
+		{"ping", ch.checkPing},
+		{"tools/list", ch.checkToolsList},
+		{"tools/call unknown tool", ch.checkUnknownTool},
+		{"tools/call invalid arguments", ch.checkInvalidArguments},
+		{"unknown method", ch.checkUnknownMethod},
+		{"cancellation", ch.checkCancellation},
+		{"shutdown", ch.checkShutdown},
+	}
+
+	for _, step := range steps {
+		check := CheckResult{Name: step.name}
+		if ch.abort != "" {
+			check.Status = StatusSkip
+			check.SkipReason = ch.abort
+			report.add(check)
+			continue
+		}
+
+		step.run(ctx, &check)
+		if ch.client.Exited() && step.name != "shutdown" {
+			check.addf(SeverityError, sectionShutdown, "server exited before being shut down")
+			ch.abort = "server exited"
+		}
+		report.add(check)
+	}
+	ch.client.Shutdown(opts.Timeout)
+
+	// Anything else on stdout breaks clients that read it as the message stream
+	stdout := CheckResult{Name: "stdout"}
+	if skipped := ch.client.SkippedLines(); skipped > 0 {
+		stdout.addf(SeverityError, sectionStdio, "server wrote %d lines to stdout that are not JSON-RPC messages", skipped)
+	}
+	report.add(stdout)
+
+	report.ServerInfo = ch.serverInfo
+	report.ProtocolVersion = ch.version
+	return report
+}
+
+// addf records a finding
+func (c *CheckResult) addf(severity Severity, section, format string, args ...interface{}) {
+	c.Findings = append(c.Findings, Finding{
+		Severity: severity,
+		Section:  section,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// request sends a request and checks the response envelope. A nil response
+// means there was none, which has been recorded as a finding.
+func (ch *Checker) request(ctx context.Context, check *CheckResult, method string, params interface{}) *client.JSONRPCResponse {
+	return ch.send(ctx, check, ch.client.NewRequest(method, params))
+}
+
+func (ch *Checker) send(ctx context.Context, check *CheckResult, request *client.JSONRPCRequest) *client.JSONRPCResponse {
+	ctx, cancel := context.WithTimeout(ctx, ch.opts.Timeout)
+	defer cancel()
+
+	response, err := ch.client.SendRequest(ctx, request)
+	if err != nil {
+		if !ch.client.Exited() {
+			check.addf(SeverityError, sectionMessages, "no response to %s: %v", request.Method, err)
+		}
+		return nil
+	}
+
+	if response.JSONRPC != "2.0" {
+		check.addf(SeverityError, sectionMessages, "response to %s has jsonrpc %q, expected \"2.0\"", request.Method, response.JSONRPC)
+	}
+	if response.Error == nil && len(response.Result) == 0 {
+		check.addf(SeverityError, sectionMessages, "response to %s has neither a result nor an error", request.Method)
+	}
+	return response
+}
+
+// toolResult decodes the isError flag of a tools/call result
+func toolResult(response *client.JSONRPCResponse) (isError bool, err error) {
+	var result struct {
+		Content []json.RawMessage `json:"content"`
+		IsError bool              `json:"isError"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return false, err
+	}
+	if result.Content == nil {
+		return false, fmt.Errorf("result has no content array")
+	}
+	return result.IsError, nil
+}
+
+func (ch *Checker) checkInitialize(ctx context.Context, check *CheckResult) {
+	ch.abort = "initialization failed"
+
+	response := ch.request(ctx, check, "initialize", client.InitializeParams{
+		ProtocolVersion: SpecVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo:      client.ClientInfo{Name: "mcp-debug-conform", Version: "1.0.0"},
+	})
+	if response == nil {
+		return
+	}
+	if response.Error != nil {
+		check.addf(SeverityError, sectionInit, "initialize failed: %s (code %d)", response.Error.Message, response.Error.Code)
+		return
+	}
+
+	var result struct {
+		ProtocolVersion string                 `json:"protocolVersion"`
+		Capabilities    map[string]interface{} `json:"capabilities"`
+		ServerInfo      *client.ServerInfo     `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		check.addf(SeverityError, sectionInit, "invalid initialize result: %v", err)
+		return
+	}
+
+	switch result.ProtocolVersion {
+	case "":
+		check.addf(SeverityError, sectionInit, "initialize result has no protocolVersion")
+	case SpecVersion:
+	default:
+		check.addf(SeverityInfo, sectionInit, "server answered protocol version %s to a request for %s; checks follow %s",
+			result.ProtocolVersion, SpecVersion, SpecVersion)
+	}
+	if result.Capabilities == nil {
+		check.addf(SeverityError, sectionInit, "initialize result has no capabilities object")
+	}
+	if result.ServerInfo == nil || result.ServerInfo.Name == "" || result.ServerInfo.Version == "" {
+		check.addf(SeverityError, sectionInit, "initialize result needs a serverInfo with name and version")
+	}
+	ch.capabilities = result.Capabilities
+	ch.serverInfo = result.ServerInfo
+	ch.version = result.ProtocolVersion
+
+	if err := ch.client.SendNotification("notifications/initialized", nil); err != nil {
+		check.addf(SeverityError, sectionInit, "failed to send initialized notification: %v", err)
+		return
+	}
+	ch.abort = ""
+}
+
+func (ch *Checker) checkPing(ctx context.Context, check *CheckResult) {
+	response := ch.request(ctx, check, "ping", nil)
+	if response == nil {
+		return
+	}
+	if response.Error != nil {
+		check.addf(SeverityError, sectionPing, "ping failed: %s (code %d)", response.Error.Message, response.Error.Code)
+		return
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(response.Result, &result); err != nil || result == nil {
+		check.addf(SeverityError, sectionPing, "ping result is not an object: %s", response.Result)
+	} else if len(result) > 0 {
+		check.addf(SeverityWarning, sectionPing, "ping result should be empty: %s", response.Result)
+	}
+}
+
+func (ch *Checker) checkToolsList(ctx context.Context, check *CheckResult) {
+	declared := client.HasCapability(ch.capabilities, "tools")
+
+	var tools []client.ToolInfo
+	cursors := make(map[string]bool)
+	cursor := ""
+	for page := 0; ; page++ {
+		var params interface{}
+		if cursor != "" {
+			params = client.CursorParams{Cursor: cursor}
+		}
+		response := ch.request(ctx, check, "tools/list", params)
+		if response == nil {
+			return
+		}
+		if response.Error != nil {
+			if !declared && page == 0 {
+				check.Status = StatusSkip
+				check.SkipReason = "server does not declare the tools capability"
+				return
+			}
+			check.addf(SeverityError, sectionToolsList, "tools/list failed: %s (code %d)", response.Error.Message, response.Error.Code)
+			return
+		}
+
+		var result struct {
+			Tools      []client.ToolInfo `json:"tools"`
+			NextCursor string            `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			check.addf(SeverityError, sectionToolsList, "invalid tools/list result: %v", err)
+			return
+		}
+		tools = append(tools, result.Tools...)
+
+		if result.NextCursor == "" {
+			break
+		}
+		if cursors[result.NextCursor] {
+			check.addf(SeverityError, sectionPagination, "cursor %q was returned twice, pagination never ends", result.NextCursor)
+			break
+		}
+		if page+1 >= maxPages {
+			check.addf(SeverityWarning, sectionPagination, "stopped after %d pages", maxPages)
+			break
+		}
+		cursors[result.NextCursor] = true
+		cursor = result.NextCursor
+	}
+
+	if !declared && len(tools) > 0 {
+		check.addf(SeverityError, sectionToolsCaps, "server lists tools but does not declare the tools capability")
+	}
+
+	names := make(map[string]bool)
+	for _, tool := range tools {
+		if tool.Name == "" {
+			check.addf(SeverityError, sectionTool, "a tool has no name")
+			continue
+		}
+		if names[tool.Name] {
+			check.addf(SeverityError, sectionTool, "tool %s is listed more than once", tool.Name)
+		}
+		names[tool.Name] = true
+
+		var schema struct {
+			Type string `json:"type"`
+		}
+		if len(tool.InputSchema) == 0 {
+			check.addf(SeverityError, sectionTool, "tool %s has no inputSchema", tool.Name)
+		} else if err := json.Unmarshal(tool.InputSchema, &schema); err != nil || schema.Type != "object" {
+			check.addf(SeverityError, sectionTool, "inputSchema of tool %s is not a schema of type object", tool.Name)
+		}
+		if tool.Description == "" {
+			check.addf(SeverityInfo, sectionTool, "tool %s has no description", tool.Name)
+		}
+	}
+	ch.tools = tools
+
+	// Invalid cursors should be rejected rather than silently restarting the list
+	response := ch.request(ctx, check, "tools/list", client.CursorParams{Cursor: "mcp-debug-invalid-cursor"})
+	switch {
+	case response == nil:
+	case response.Error == nil:
+		check.addf(SeverityWarning, sectionPagination, "tools/list accepted an invalid cursor, expected error code %d", codeInvalidParams)
+	case response.Error.Code != codeInvalidParams:
+		check.addf(SeverityWarning, sectionPagination, "tools/list rejected an invalid cursor with code %d, expected %d", response.Error.Code, codeInvalidParams)
+	}
+}
+
+func (ch *Checker) checkUnknownTool(ctx context.Context, check *CheckResult) {
+	if !client.HasCapability(ch.capabilities, "tools") {
+		check.Status = StatusSkip
+		check.SkipReason = "server does not declare the tools capability"
+		return
+	}
+
+	response := ch.request(ctx, check, "tools/call", client.CallToolParams{
+		Name:      "mcp-debug-no-such-tool",
+		Arguments: map[string]interface{}{},
+	})
+	if response == nil || response.Error != nil {
+		return
+	}
+
+	isError, err := toolResult(response)
+	switch {
+	case err != nil:
+		check.addf(SeverityError, sectionToolErrors, "invalid tools/call result: %v", err)
+	case isError:
+		check.addf(SeverityInfo, sectionToolErrors, "unknown tool reported as a tool result with isError; the spec uses a JSON-RPC error (code %d)", codeInvalidParams)
+	default:
+		check.addf(SeverityWarning, sectionToolErrors, "call of an unknown tool succeeded")
+	}
+}
+
+// checkInvalidArguments calls each tool with required arguments without
+// any, which no tool should act on. Tools without required arguments are
+// not called, since any call might do real work.
+func (ch *Checker) checkInvalidArguments(ctx context.Context, check *CheckResult) {
+	called := 0
+	for _, tool := range ch.tools {
+		var schema struct {
+			Required []string `json:"required"`
+		}
+		if json.Unmarshal(tool.InputSchema, &schema) != nil || len(schema.Required) == 0 {
+			continue
+		}
+		sort.Strings(schema.Required)
+		called++
+
+		response := ch.request(ctx, check, "tools/call", client.CallToolParams{
+			Name:      tool.Name,
+			Arguments: map[string]interface{}{},
+		})
+		if response == nil {
+			if ch.client.Exited() {
+				check.addf(SeverityError, sectionToolErrors, "server exited when %s was called without its required arguments", tool.Name)
+				return
+			}
+			continue
+		}
+		if response.Error != nil {
+			continue
+		}
+
+		isError, err := toolResult(response)
+		switch {
+		case err != nil:
+			check.addf(SeverityError, sectionToolErrors, "invalid result from %s: %v", tool.Name, err)
+		case !isError:
+			check.addf(SeverityWarning, sectionToolErrors, "%s succeeded without its required argument %q", tool.Name, schema.Required[0])
+		}
+	}
+
+	if called == 0 {
+		check.Status = StatusSkip
+		check.SkipReason = "no tool has required arguments"
+	}
+}
+
+func (ch *Checker) checkUnknownMethod(ctx context.Context, check *CheckResult) {
+	response := ch.request(ctx, check, "mcp-debug/unknown-method", nil)
+	switch {
+	case response == nil:
+	case response.Error == nil:
+		check.addf(SeverityError, sectionMessages, "unknown method answered with a result, expected error code %d", codeMethodNotFound)
+	case response.Error.Code != codeMethodNotFound:
+		check.addf(SeverityWarning, sectionMessages, "unknown method rejected with code %d, expected %d", response.Error.Code, codeMethodNotFound)
+	}
+
+	// Unknown notifications must be ignored
+	if err := ch.client.SendNotification("notifications/mcp-debug-unknown", nil); err != nil {
+		check.addf(SeverityError, sectionMessages, "failed to send notification: %v", err)
+		return
+	}
+	if response := ch.request(ctx, check, "ping", nil); response != nil && response.Error != nil {
+		check.addf(SeverityError, sectionPing, "ping failed after an unknown notification: %s", response.Error.Message)
+	}
+}
+
+// checkCancellation cancels a request that was never sent and one that is
+// in flight; the server must keep working either way. Whether the in-flight
+// request is still running when the cancellation arrives is up to timing.
+func (ch *Checker) checkCancellation(ctx context.Context, check *CheckResult) {
+	unknown := ch.client.NewRequest("ping", nil)
+	if err := ch.client.SendNotification("notifications/cancelled", map[string]interface{}{
+		"requestId": unknown.ID,
+		"reason":    "conformance check: unknown request",
+	}); err != nil {
+		check.addf(SeverityError, sectionCancellation, "failed to send cancellation: %v", err)
+		return
+	}
+
+	method := "ping"
+	if client.HasCapability(ch.capabilities, "tools") {
+		method = "tools/list"
+	}
+	request := ch.client.NewRequest(method, nil)
+	answered := make(chan struct{})
+	go func() {
+		// A server honoring the cancellation never answers
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		if _, err := ch.client.SendRequest(waitCtx, request); err == nil {
+			close(answered)
+		}
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := ch.client.SendNotification("notifications/cancelled", map[string]interface{}{
+		"requestId": request.ID,
+		"reason":    "conformance check: in-flight request",
+	}); err != nil {
+		check.addf(SeverityError, sectionCancellation, "failed to send cancellation: %v", err)
+		return
+	}
+
+	select {
+	case <-answered:
+		check.addf(SeverityInfo, sectionCancellation, "cancelled %s was answered, which is allowed if it had already finished", method)
+	case <-time.After(time.Second):
+	}
+
+	if response := ch.request(ctx, check, "ping", nil); response != nil && response.Error != nil {
+		check.addf(SeverityError, sectionCancellation, "ping failed after cancellation: %s", response.Error.Message)
+	}
+}
+
+func (ch *Checker) checkShutdown(ctx context.Context, check *CheckResult) {
+	exited, err := ch.client.Shutdown(ch.opts.Timeout)
+	if !exited {
+		check.addf(SeverityWarning, sectionShutdown, "server did not exit within %v of its input closing and was killed", ch.opts.Timeout)
+	} else if err != nil {
+		check.addf(SeverityInfo, sectionShutdown, "server exited with %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package conform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"mcp-debug/client"
+)
+
+// Severity says how seriously a finding departs from the spec
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // breaks a MUST of the spec
+	SeverityWarning Severity = "warning" // breaks a SHOULD of the spec
+	SeverityInfo    Severity = "info"    // allowed, but worth knowing
+)
+
+// Check statuses
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// SpecVersion is the protocol revision the checker speaks and its spec
+// sections refer to
+const SpecVersion = "2024-11-05"
+
+// Finding is one departure from the spec
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Section  string   `json:"section"`
+	Message  string   `json:"message"`
+}
+
+// CheckResult is the outcome of one step of the lifecycle
+type CheckResult struct {
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	SkipReason string    `json:"skipReason,omitempty"`
+	Findings   []Finding `json:"findings,omitempty"`
+}
+
+// Summary counts findings by severity
+type Summary struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Info     int `json:"info"`
+}
+
+// Report is the result of a conformance run
+type Report struct {
+	Command         []string           `json:"command"`
+	SpecVersion     string             `json:"specVersion"`
+	ServerInfo      *client.ServerInfo `json:"serverInfo,omitempty"`
+	ProtocolVersion string             `json:"protocolVersion,omitempty"`
+	Checks          []CheckResult      `json:"checks"`
+	Summary         Summary            `json:"summary"`
+}
+
+// add appends a check result, updating the summary
+func (r *Report) add(check CheckResult) {
+	if check.Status == "" {
+		check.Status = StatusPass
+		for _, finding := range check.Findings {
+			if finding.Severity != SeverityInfo {
+				check.Status = StatusFail
+			}
+		}
+	}
+
+	for _, finding := range check.Findings {
+		switch finding.Severity {
+		case SeverityError:
+			r.Summary.Errors++
+		case SeverityWarning:
+			r.Summary.Warnings++
+		default:
+			r.Summary.Info++
+		}
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report for reading in a terminal
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Conformance report for %s\n", strings.Join(r.Command, " "))
+	if r.ServerInfo != nil {
+		fmt.Fprintf(w, "Server: %s %s, protocol %s\n", r.ServerInfo.Name, r.ServerInfo.Version, r.ProtocolVersion)
+	}
+	fmt.Fprintf(w, "Spec sections refer to revision %s\n\n", r.SpecVersion)
+
+	for _, check := range r.Checks {
+		if check.Status == StatusSkip {
+			fmt.Fprintf(w, "SKIP  %s (%s)\n", check.Name, check.SkipReason)
+			continue
+		}
+		fmt.Fprintf(w, "%-4s  %s\n", strings.ToUpper(check.Status), check.Name)
+		for _, finding := range check.Findings {
+			fmt.Fprintf(w, "      %-7s [%s] %s\n", finding.Severity, finding.Section, finding.Message)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d errors, %d warnings, %d info\n", r.Summary.Errors, r.Summary.Warnings, r.Summary.Info)
+}
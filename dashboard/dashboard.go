@@ -0,0 +1,252 @@
+// Package dashboard serves a local web UI for a running proxy: its servers
+// and their state, the registered tools with their schemas, a live stream of
+// tool calls, and buttons to disconnect, reconnect and restart servers.
+package dashboard
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-debug/logging"
+	"mcp-debug/redact"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+const (
+	historySize    = 200              // calls replayed to a newly opened page
+	subscriberSize = 256              // calls buffered per page before dropping
+	maxPayload     = 16 << 10         // bytes of arguments or result text shown
+	keepAlive      = 15 * time.Second // comment sent on idle event streams
+)
+
+// Call states
+const (
+	StatePending   = "pending"
+	StateOK        = "ok"
+	StateToolError = "tool_error" // result with isError set
+	StateError     = "error"      // the call failed
+)
+
+// Server is a server as shown on the dashboard
+type Server struct {
+	Name      string `json:"name"`
+	Static    bool   `json:"static"` // from the config; cannot be disconnected
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"`
+	Command   string `json:"command"`
+	Tools     int    `json:"tools"`
+	Circuit   string `json:"circuit"`
+	Chaos     string `json:"chaos,omitempty"`
+}
+
+// Tool is a registered tool as shown on the dashboard
+type Tool struct {
+	Name         string          `json:"name"`
+	Server       string          `json:"server"`
+	OriginalName string          `json:"originalName"`
+	Description  string          `json:"description"`
+	InputSchema  json.RawMessage `json:"inputSchema,omitempty"`
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+}
+
+// Backend is the proxy as seen by the dashboard. The actions return a
+// message for the user, or an error if nothing was done.
+type Backend interface {
+	Servers() []Server
+	Tools() []Tool
+	Disconnect(ctx context.Context, name string) (string, error)
+	Reconnect(ctx context.Context, name string) (string, error)
+	Restart(ctx context.Context, name string) (string, error)
+}
+
+// Call is a tool call in the live stream, sent when it starts and again
+// when it ends
+type Call struct {
+	ID         int64           `json:"id"`
+	RequestID  string          `json:"requestId,omitempty"` // as in the proxy log
+	Time       time.Time       `json:"time"`
+	Server     string          `json:"server"`
+	Tool       string          `json:"tool"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	State      string          `json:"state"`
+	Result     string          `json:"result,omitempty"`
+	DurationMS float64         `json:"durationMs,omitempty"`
+}
+
+// Dashboard streams tool calls to open pages and serves the UI. A nil
+// Dashboard records nothing.
+type Dashboard struct {
+	backend     Backend
+	history     []Call // latest state of the most recent calls, oldest first
+	nextID      int64
+	subscribers map[chan Call]struct{}
+	mu          sync.Mutex
+}
+
+// New creates a dashboard without a backend; set it with SetBackend
+func New() *Dashboard {
+	return &Dashboard{subscribers: make(map[chan Call]struct{})}
+}
+
+// SetBackend sets the proxy the dashboard shows and acts on
+func (d *Dashboard) SetBackend(backend Backend) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.backend = backend
+}
+
+func (d *Dashboard) getBackend() Backend {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.backend
+}
+
+// Instrument wraps a tool handler to show its calls in the live stream.
+// Returns the handler unchanged for a nil dashboard.
+func (d *Dashboard) Instrument(serverName, toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if d == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		d.mu.Lock()
+		d.nextID++
+		call := Call{
+			ID:        d.nextID,
+			RequestID: logging.RequestID(ctx),
+			Time:      time.Now(),
+			Server:    serverName,
+			Tool:      toolName,
+			Arguments: payload(request.GetArguments()),
+			State:     StatePending,
+		}
+		d.mu.Unlock()
+		d.publish(call)
+
+		start := time.Now()
+		result, err := handler(ctx, request)
+		call.DurationMS = float64(time.Since(start).Microseconds()) / 1000
+
+		switch {
+		case err != nil:
+			call.State = StateError
+			call.Result = truncate(redact.Default.String(err.Error()))
+		case result != nil:
+			call.State = StateOK
+			if result.IsError {
+				call.State = StateToolError
+			}
+			call.Result = truncate(redact.Default.String(resultText(result)))
+		default:
+			call.State = StateOK
+		}
+		d.publish(call)
+		return result, err
+	}
+}
+
+// payload encodes arguments for display, redacted and cut to maxPayload
+func payload(args map[string]interface{}) json.RawMessage {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil
+	}
+	data = redact.Default.JSON(data)
+	if len(data) > maxPayload {
+		// No longer valid JSON, so send it as a string
+		data, _ = json.Marshal(truncate(string(data)))
+	}
+	return data
+}
+
+func truncate(text string) string {
+	if len(text) <= maxPayload {
+		return text
+	}
+	return fmt.Sprintf("%s... (%d bytes)", strings.ToValidUTF8(text[:maxPayload], ""), len(text))
+}
+
+// resultText joins the text content of a result
+func resultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			if text != "" {
+				text += "\n"
+			}
+			text += textContent.Text
+		}
+	}
+	return text
+}
+
+// publish records the latest state of a call and sends it to open pages
+func (d *Dashboard) publish(call Call) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	replaced := false
+	for i := len(d.history) - 1; i >= 0; i-- {
+		if d.history[i].ID == call.ID {
+			d.history[i] = call
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		d.history = append(d.history, call)
+		if len(d.history) > historySize {
+			d.history = append([]Call(nil), d.history[len(d.history)-historySize:]...)
+		}
+	}
+
+	for subscriber := range d.subscribers {
+		select {
+		case subscriber <- call:
+		default:
+			// The page is not keeping up; it misses this update
+		}
+	}
+}
+
+// subscribe returns the recent calls and a channel receiving new ones
+func (d *Dashboard) subscribe() ([]Call, chan Call) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	subscriber := make(chan Call, subscriberSize)
+	d.subscribers[subscriber] = struct{}{}
+	return append([]Call(nil), d.history...), subscriber
+}
+
+func (d *Dashboard) unsubscribe(subscriber chan Call) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.subscribers, subscriber)
+}
+
+// Listen serves the dashboard on addr. It returns once the address is
+// bound; the server runs until the process exits.
+func (d *Dashboard) Listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for dashboard: %w", err)
+	}
+	go http.Serve(listener, d.Handler())
+	return nil
+}
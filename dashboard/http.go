@@ -0,0 +1,155 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// actionHeader must be set on requests that change anything. Browsers only
+// send custom headers to other sites after a CORS preflight, which the
+// dashboard never allows, so other web pages cannot press its buttons.
+const actionHeader = "X-MCP-Debug"
+
+// Handler returns the HTTP handler of the UI and its API
+func (d *Dashboard) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", d.serveIndex)
+	mux.HandleFunc("GET /api/servers", d.serveServers)
+	mux.HandleFunc("GET /api/tools", d.serveTools)
+	mux.HandleFunc("GET /api/calls", d.serveCalls)
+	mux.HandleFunc("POST /api/servers/{name}/{action}", d.serveAction)
+	return localOnly(mux)
+}
+
+// localOnly refuses requests addressed to anything but localhost or a
+// loopback address. A page on another site can point its own name at
+// 127.0.0.1 (DNS rebinding); it can still only reach the dashboard by that
+// name.
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if ip := net.ParseIP(strings.Trim(host, "[]")); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			http.Error(w, "dashboard is only served on localhost", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (d *Dashboard) serveIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+func (d *Dashboard) serveServers(w http.ResponseWriter, r *http.Request) {
+	servers := []Server{}
+	if backend := d.getBackend(); backend != nil {
+		servers = append(servers, backend.Servers()...)
+	}
+	writeJSON(w, http.StatusOK, servers)
+}
+
+func (d *Dashboard) serveTools(w http.ResponseWriter, r *http.Request) {
+	tools := []Tool{}
+	if backend := d.getBackend(); backend != nil {
+		tools = append(tools, backend.Tools()...)
+	}
+	writeJSON(w, http.StatusOK, tools)
+}
+
+// serveCalls streams calls as server-sent events, starting with the recent
+// ones
+func (d *Dashboard) serveCalls(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	history, subscriber := d.subscribe()
+	defer d.unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	for _, call := range history {
+		writeEvent(w, call)
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case call := <-subscriber:
+			writeEvent(w, call)
+			flusher.Flush()
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, call Call) {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: call\ndata: %s\n\n", data)
+}
+
+// serveAction disconnects, reconnects or restarts a server. Servers are
+// always started with their current command: changing it runs a new program,
+// which is left to the server_reconnect tool.
+func (d *Dashboard) serveAction(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(actionHeader) == "" {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "missing " + actionHeader + " header"})
+		return
+	}
+	backend := d.getBackend()
+	if backend == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "proxy not ready"})
+		return
+	}
+
+	// Server processes are tied to the context they are started with, so it
+	// must outlive the request
+	name := r.PathValue("name")
+	ctx := context.WithoutCancel(r.Context())
+
+	var message string
+	var err error
+	switch action := r.PathValue("action"); action {
+	case "disconnect":
+		message, err = backend.Disconnect(ctx, name)
+	case "reconnect":
+		message, err = backend.Reconnect(ctx, name)
+	case "restart":
+		message, err = backend.Restart(ctx, name)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown action '%s'", action)})
+		return
+	}
+
+	if err != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
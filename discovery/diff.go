@@ -0,0 +1,323 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mcp-debug/client"
+)
+
+// ToolDiff describes how a server's tool set changed between two discoveries
+type ToolDiff struct {
+	Added     []RemoteTool `json:"added"`
+	Removed   []RemoteTool `json:"removed"`
+	Changed   []ToolChange `json:"changed"`
+	Unchanged []RemoteTool `json:"unchanged"`
+}
+
+// ToolChange describes a tool that exists in both tool sets but differs
+type ToolChange struct {
+	Tool               RemoteTool `json:"tool"`
+	DescriptionChanged bool       `json:"descriptionChanged"`
+	SchemaChanges      []string   `json:"schemaChanges,omitempty"`
+	OutputChanges      []string   `json:"outputChanges,omitempty"`
+	AnnotationChanges  []string   `json:"annotationChanges,omitempty"`
+}
+
+// IsEmpty returns true if no tools were added, removed or changed
+func (d *ToolDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffTools compares two tool sets by prefixed name
+func DiffTools(oldTools, newTools []RemoteTool) *ToolDiff {
+	diff := &ToolDiff{}
+
+	oldByName := make(map[string]RemoteTool, len(oldTools))
+	for _, tool := range oldTools {
+		oldByName[tool.PrefixedName] = tool
+	}
+
+	seen := make(map[string]bool, len(newTools))
+	for _, tool := range newTools {
+		seen[tool.PrefixedName] = true
+
+		old, exists := oldByName[tool.PrefixedName]
+		if !exists {
+			diff.Added = append(diff.Added, tool)
+			continue
+		}
+
+		change := ToolChange{
+			Tool:               tool,
+			DescriptionChanged: old.Description != tool.Description,
+			SchemaChanges:      DiffSchemas(old.InputSchema, tool.InputSchema),
+			OutputChanges:      diffOutputSchemas(old.OutputSchema, tool.OutputSchema),
+			AnnotationChanges:  diffAnnotations(old.Annotations, tool.Annotations),
+		}
+		if change.DescriptionChanged || len(change.SchemaChanges) > 0 ||
+			len(change.OutputChanges) > 0 || len(change.AnnotationChanges) > 0 {
+			diff.Changed = append(diff.Changed, change)
+		} else {
+			diff.Unchanged = append(diff.Unchanged, tool)
+		}
+	}
+
+	for _, tool := range oldTools {
+		if !seen[tool.PrefixedName] {
+			diff.Removed = append(diff.Removed, tool)
+		}
+	}
+
+	return diff
+}
+
+// DiffSchemas returns human-readable differences between two schemas.
+// Properties are compared recursively, including those of array items:
+// added, removed, type changes and changes to the required lists, with nested
+// properties named by their path ("filter.limit", "rows[].id"). Any other
+// difference is reported as a generic schema change.
+func DiffSchemas(oldSchema, newSchema json.RawMessage) []string {
+	if jsonEqual(oldSchema, newSchema) {
+		return nil
+	}
+
+	changes, ok := diffSchema("", orEmptyObject(oldSchema), orEmptyObject(newSchema))
+	if !ok {
+		return []string{"schema changed"}
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "schema changed")
+	}
+	return changes
+}
+
+// diffSchema compares the properties, required lists and array items of two
+// schemas whose properties are named with the path prefix. Returns false if
+// either schema is not a JSON object.
+func diffSchema(path string, oldSchema, newSchema json.RawMessage) ([]string, bool) {
+	var oldParsed, newParsed schemaSummary
+	oldErr := json.Unmarshal(oldSchema, &oldParsed)
+	newErr := json.Unmarshal(newSchema, &newParsed)
+	if oldErr != nil || newErr != nil {
+		return nil, false
+	}
+
+	var changes []string
+
+	for _, name := range sortedKeys(newParsed.Properties) {
+		newProp := newParsed.Properties[name]
+		oldProp, exists := oldParsed.Properties[name]
+		if !exists {
+			changes = append(changes, fmt.Sprintf("+ property %s%s (%s)", path, name, propertyType(newProp)))
+			continue
+		}
+		changes = append(changes, diffProperty(path+name, oldProp, newProp)...)
+	}
+
+	for _, name := range sortedKeys(oldParsed.Properties) {
+		if _, exists := newParsed.Properties[name]; !exists {
+			changes = append(changes, fmt.Sprintf("- property %s%s", path, name))
+		}
+	}
+
+	oldRequired := toSet(oldParsed.Required)
+	newRequired := toSet(newParsed.Required)
+	for _, name := range newParsed.Required {
+		if !oldRequired[name] {
+			changes = append(changes, fmt.Sprintf("+ required %s%s", path, name))
+		}
+	}
+	for _, name := range oldParsed.Required {
+		if !newRequired[name] {
+			changes = append(changes, fmt.Sprintf("- required %s%s", path, name))
+		}
+	}
+
+	if len(oldParsed.Items) > 0 && len(newParsed.Items) > 0 {
+		changes = append(changes, diffProperty(strings.TrimSuffix(path, ".")+"[]", oldParsed.Items, newParsed.Items)...)
+	}
+
+	return changes, true
+}
+
+// diffProperty compares a property present in both schemas, descending into
+// object properties and array items when its type is unchanged
+func diffProperty(path string, oldProp, newProp json.RawMessage) []string {
+	if propertyType(oldProp) != propertyType(newProp) {
+		return []string{fmt.Sprintf("~ property %s type %s -> %s", path, propertyType(oldProp), propertyType(newProp))}
+	}
+	if jsonEqual(oldProp, newProp) {
+		return nil
+	}
+	if nested, ok := diffSchema(path+".", oldProp, newProp); ok && len(nested) > 0 {
+		return nested
+	}
+	return []string{fmt.Sprintf("~ property %s definition changed", path)}
+}
+
+// diffOutputSchemas describes how a tool's output schema changed, including
+// a schema being added or removed
+func diffOutputSchemas(oldSchema, newSchema json.RawMessage) []string {
+	oldEmpty := len(bytes.TrimSpace(oldSchema)) == 0
+	newEmpty := len(bytes.TrimSpace(newSchema)) == 0
+	switch {
+	case oldEmpty && newEmpty:
+		return nil
+	case oldEmpty:
+		return []string{"+ output schema"}
+	case newEmpty:
+		return []string{"- output schema"}
+	}
+	return DiffSchemas(oldSchema, newSchema)
+}
+
+// diffAnnotations describes changed tool annotations, e.g. "~ readOnlyHint
+// unset -> true"
+func diffAnnotations(oldAnnotations, newAnnotations *client.ToolAnnotations) []string {
+	if oldAnnotations == nil {
+		oldAnnotations = &client.ToolAnnotations{}
+	}
+	if newAnnotations == nil {
+		newAnnotations = &client.ToolAnnotations{}
+	}
+
+	var changes []string
+	if oldAnnotations.Title != newAnnotations.Title {
+		changes = append(changes, fmt.Sprintf("~ title %q -> %q", oldAnnotations.Title, newAnnotations.Title))
+	}
+	hints := []struct {
+		name     string
+		old, new *bool
+	}{
+		{"readOnlyHint", oldAnnotations.ReadOnlyHint, newAnnotations.ReadOnlyHint},
+		{"destructiveHint", oldAnnotations.DestructiveHint, newAnnotations.DestructiveHint},
+		{"idempotentHint", oldAnnotations.IdempotentHint, newAnnotations.IdempotentHint},
+		{"openWorldHint", oldAnnotations.OpenWorldHint, newAnnotations.OpenWorldHint},
+	}
+	for _, hint := range hints {
+		if oldValue, newValue := hintString(hint.old), hintString(hint.new); oldValue != newValue {
+			changes = append(changes, fmt.Sprintf("~ %s %s -> %s", hint.name, oldValue, newValue))
+		}
+	}
+	return changes
+}
+
+func hintString(hint *bool) string {
+	if hint == nil {
+		return "unset"
+	}
+	return fmt.Sprint(*hint)
+}
+
+// FormatToolDiff renders a diff as text suitable for a tool result
+func FormatToolDiff(diff *ToolDiff) string {
+	if diff.IsEmpty() {
+		return fmt.Sprintf("Tools unchanged (%d tools).", len(diff.Unchanged))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Tool changes: %d added, %d removed, %d changed, %d unchanged\n",
+		len(diff.Added), len(diff.Removed), len(diff.Changed), len(diff.Unchanged)))
+
+	for _, tool := range diff.Added {
+		b.WriteString(fmt.Sprintf("+ %s\n", tool.PrefixedName))
+	}
+	for _, tool := range diff.Removed {
+		b.WriteString(fmt.Sprintf("- %s\n", tool.PrefixedName))
+	}
+	for _, change := range diff.Changed {
+		b.WriteString(fmt.Sprintf("~ %s\n", change.Tool.PrefixedName))
+		if change.DescriptionChanged {
+			b.WriteString("    description changed\n")
+		}
+		for _, schemaChange := range change.SchemaChanges {
+			b.WriteString(fmt.Sprintf("    %s\n", schemaChange))
+		}
+		for _, outputChange := range change.OutputChanges {
+			b.WriteString(fmt.Sprintf("    output: %s\n", outputChange))
+		}
+		for _, annotationChange := range change.AnnotationChanges {
+			b.WriteString(fmt.Sprintf("    %s\n", annotationChange))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// schemaSummary holds the parts of a JSON schema that DiffSchemas compares
+type schemaSummary struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+	Required   []string                   `json:"required"`
+	Items      json.RawMessage            `json:"items"`
+}
+
+// propertyType extracts the "type" keyword of a property schema
+func propertyType(prop json.RawMessage) string {
+	var typed struct {
+		Type interface{} `json:"type"`
+	}
+	if err := json.Unmarshal(prop, &typed); err != nil || typed.Type == nil {
+		return "any"
+	}
+
+	switch t := typed.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, v := range t {
+			parts = append(parts, fmt.Sprint(v))
+		}
+		return strings.Join(parts, "|")
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// jsonEqual compares two JSON documents ignoring formatting and key order
+func jsonEqual(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var av, bv interface{}
+	if err := json.Unmarshal(orEmptyObject(a), &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(orEmptyObject(b), &bv); err != nil {
+		return false
+	}
+
+	// encoding/json sorts map keys, giving a canonical form
+	ac, _ := json.Marshal(av)
+	bc, _ := json.Marshal(bv)
+	return bytes.Equal(ac, bc)
+}
+
+func orEmptyObject(raw json.RawMessage) json.RawMessage {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return json.RawMessage("{}")
+	}
+	return raw
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,171 @@
+package discovery
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mcp-debug/client"
+)
+
+func tool(name, description, schema string) RemoteTool {
+	return RemoteTool{PrefixedName: name, Description: description, InputSchema: json.RawMessage(schema)}
+}
+
+func toolNames(tools []RemoteTool) []string {
+	var names []string
+	for _, t := range tools {
+		names = append(names, t.PrefixedName)
+	}
+	return names
+}
+
+func TestDiffTools(t *testing.T) {
+	oldTools := []RemoteTool{
+		tool("s_keep", "same", `{"type":"object"}`),
+		tool("s_gone", "removed", `{}`),
+		tool("s_desc", "old description", `{}`),
+		tool("s_schema", "same", `{"properties":{"a":{"type":"string"}}}`),
+	}
+	newTools := []RemoteTool{
+		tool("s_keep", "same", `{ "type": "object" }`),
+		tool("s_desc", "new description", `{}`),
+		tool("s_schema", "same", `{"properties":{"a":{"type":"number"}}}`),
+		tool("s_new", "added", `{}`),
+	}
+
+	diff := DiffTools(oldTools, newTools)
+	if got := toolNames(diff.Added); !reflect.DeepEqual(got, []string{"s_new"}) {
+		t.Errorf("Added = %v, want [s_new]", got)
+	}
+	if got := toolNames(diff.Removed); !reflect.DeepEqual(got, []string{"s_gone"}) {
+		t.Errorf("Removed = %v, want [s_gone]", got)
+	}
+	if got := toolNames(diff.Unchanged); !reflect.DeepEqual(got, []string{"s_keep"}) {
+		t.Errorf("Unchanged = %v, want [s_keep]", got)
+	}
+	if len(diff.Changed) != 2 {
+		t.Fatalf("Changed = %+v, want 2 changes", diff.Changed)
+	}
+	if c := diff.Changed[0]; c.Tool.PrefixedName != "s_desc" || !c.DescriptionChanged || len(c.SchemaChanges) > 0 {
+		t.Errorf("Changed[0] = %+v, want description change of s_desc", c)
+	}
+	if c := diff.Changed[1]; c.Tool.PrefixedName != "s_schema" || c.DescriptionChanged ||
+		!reflect.DeepEqual(c.SchemaChanges, []string{"~ property a type string -> number"}) {
+		t.Errorf("Changed[1] = %+v, want type change of s_schema", c)
+	}
+	if diff.IsEmpty() {
+		t.Errorf("IsEmpty() = true for a diff with changes")
+	}
+}
+
+func TestDiffToolsUnchanged(t *testing.T) {
+	tools := []RemoteTool{tool("s_a", "a", `{}`), tool("s_b", "b", ``)}
+	diff := DiffTools(tools, tools)
+	if !diff.IsEmpty() || len(diff.Unchanged) != 2 {
+		t.Errorf("DiffTools() = %+v, want 2 unchanged tools", diff)
+	}
+	if got := FormatToolDiff(diff); got != "Tools unchanged (2 tools)." {
+		t.Errorf("FormatToolDiff() = %q", got)
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldSchema string
+		newSchema string
+		want      []string
+	}{
+		{"equal ignoring formatting", `{"a":1,"b":2}`, `{ "b": 2, "a": 1 }`, nil},
+		{"empty and empty object", ``, `{}`, nil},
+		{"added property", `{}`, `{"properties":{"x":{"type":"integer"}}}`, []string{"+ property x (integer)"}},
+		{"removed property", `{"properties":{"x":{}}}`, `{"properties":{}}`, []string{"- property x"}},
+		{"required changes", `{"required":["a"]}`, `{"required":["b"]}`, []string{"+ required b", "- required a"}},
+		{"union type", `{"properties":{"x":{"type":"string"}}}`, `{"properties":{"x":{"type":["string","null"]}}}`,
+			[]string{"~ property x type string -> string|null"}},
+		{"nested property", `{"properties":{"f":{"type":"object","properties":{"limit":{"type":"integer"}}}}}`,
+			`{"properties":{"f":{"type":"object","properties":{"limit":{"type":"string"}}}}}`,
+			[]string{"~ property f.limit type integer -> string"}},
+		{"array items", `{"properties":{"rows":{"type":"array","items":{"properties":{"id":{"type":"string"}}}}}}`,
+			`{"properties":{"rows":{"type":"array","items":{"properties":{}}}}}`,
+			[]string{"- property rows[].id"}},
+		{"property definition", `{"properties":{"x":{"type":"string"}}}`, `{"properties":{"x":{"type":"string","enum":["a"]}}}`,
+			[]string{"~ property x definition changed"}},
+		{"other keyword", `{"type":"object"}`, `{"type":"object","additionalProperties":false}`, []string{"schema changed"}},
+		{"not an object", `[]`, `{}`, []string{"schema changed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffSchemas(json.RawMessage(tt.oldSchema), json.RawMessage(tt.newSchema))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffSchemas() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffOutputSchemas(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldSchema string
+		newSchema string
+		want      []string
+	}{
+		{"both empty", ``, ` `, nil},
+		{"added", ``, `{"type":"object"}`, []string{"+ output schema"}},
+		{"removed", `{"type":"object"}`, ``, []string{"- output schema"}},
+		{"changed", `{"properties":{}}`, `{"properties":{"n":{"type":"number"}}}`, []string{"+ property n (number)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffOutputSchemas(json.RawMessage(tt.oldSchema), json.RawMessage(tt.newSchema))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffOutputSchemas() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffAnnotations(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name    string
+		oldAnno *client.ToolAnnotations
+		newAnno *client.ToolAnnotations
+		want    []string
+	}{
+		{"both nil", nil, nil, nil},
+		{"nil and empty", nil, &client.ToolAnnotations{}, nil},
+		{"hint set", nil, &client.ToolAnnotations{ReadOnlyHint: &yes}, []string{"~ readOnlyHint unset -> true"}},
+		{"hint flipped", &client.ToolAnnotations{DestructiveHint: &yes}, &client.ToolAnnotations{DestructiveHint: &no},
+			[]string{"~ destructiveHint true -> false"}},
+		{"title", &client.ToolAnnotations{Title: "Old"}, &client.ToolAnnotations{Title: "New"}, []string{`~ title "Old" -> "New"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffAnnotations(tt.oldAnno, tt.newAnno); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffAnnotations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatToolDiff(t *testing.T) {
+	diff := DiffTools(
+		[]RemoteTool{tool("s_a", "a", `{}`), tool("s_b", "b", `{}`)},
+		[]RemoteTool{tool("s_a", "changed", `{"required":["x"]}`), tool("s_c", "c", `{}`)},
+	)
+	want := strings.Join([]string{
+		"Tool changes: 1 added, 1 removed, 1 changed, 0 unchanged",
+		"+ s_c",
+		"- s_b",
+		"~ s_a",
+		"    description changed",
+		"    + required x",
+	}, "\n")
+	if got := FormatToolDiff(diff); got != want {
+		t.Errorf("FormatToolDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
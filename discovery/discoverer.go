@@ -13,12 +13,14 @@ import (
 // Discoverer handles tool discovery from multiple MCP servers
 type Discoverer struct {
 	config *config.ProxyConfig
+	naming *NamingPolicy
 }
 
 // NewDiscoverer creates a new tool discoverer
 func NewDiscoverer(cfg *config.ProxyConfig) *Discoverer {
 	return &Discoverer{
 		config: cfg,
+		naming: NewNamingPolicy(cfg),
 	}
 }
 
@@ -109,13 +111,26 @@ func (d *Discoverer) discoverServer(ctx context.Context, serverConfig config.Ser
 		return result
 	}
 	
-	// Convert to prefixed tools
+	// Convert to prefixed tools, skipping those filtered out by the config
 	for _, toolInfo := range toolInfos {
-		remoteTool := CreatePrefixedTool(serverConfig.Name, serverConfig.Prefix, ToolInfo{
+		if !serverConfig.Tools.Allows(toolInfo.Name) {
+			result.HiddenTools = append(result.HiddenTools, toolInfo.Name)
+			continue
+		}
+		
+		rules := serverConfig.ArgumentRulesFor(toolInfo.Name)
+		remoteTool := CreatePrefixedTool(d.naming, serverConfig.Name, serverConfig.Prefix, ToolInfo{
 			Name:        toolInfo.Name,
 			Description: toolInfo.Description,
-			InputSchema: toolInfo.InputSchema,
+			InputSchema:  rules.ExposedSchema(toolInfo.InputSchema),
+			OutputSchema: toolInfo.OutputSchema,
+			Annotations:  toolInfo.Annotations,
 		})
+		remoteTool.ArgumentRules = rules
+		remoteTool.TransformRules = serverConfig.TransformRulesFor(toolInfo.Name)
+		remoteTool.CacheTTL = d.config.CacheTTLFor(&serverConfig, toolInfo.Name, toolInfo.Annotations.IsReadOnly())
+		remoteTool.InputValidation = d.config.InputValidationFor(&serverConfig)
+		remoteTool.OutputValidation = d.config.OutputValidationFor(&serverConfig)
 		result.Tools = append(result.Tools, remoteTool)
 	}
 	
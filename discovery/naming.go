@@ -0,0 +1,88 @@
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"unicode/utf8"
+
+	"mcp-debug/config"
+)
+
+// NamingPolicy decides the names under which remote tools and prompts are
+// exposed. The original name is always kept alongside the exposed one, so
+// routing never has to reverse the transformation.
+type NamingPolicy struct {
+	separator string
+	noPrefix  bool
+	maxLength int
+	renames   map[string]map[string]string // server name -> original tool name -> exposed name
+}
+
+// NewNamingPolicy creates a naming policy from the proxy configuration
+func NewNamingPolicy(cfg *config.ProxyConfig) *NamingPolicy {
+	if cfg == nil {
+		return DefaultNamingPolicy()
+	}
+
+	naming := cfg.GetNaming()
+	policy := &NamingPolicy{
+		separator: naming.Separator,
+		noPrefix:  naming.NoPrefix,
+		maxLength: naming.MaxLength,
+		renames:   make(map[string]map[string]string),
+	}
+	for _, server := range cfg.Servers {
+		if len(server.Rename) > 0 {
+			policy.renames[server.Name] = server.Rename
+		}
+	}
+	return policy
+}
+
+// DefaultNamingPolicy returns the policy used without configuration: prefix
+// and name joined by "_", shortened to 64 characters
+func DefaultNamingPolicy() *NamingPolicy {
+	return NewNamingPolicy(&config.ProxyConfig{})
+}
+
+// ToolName returns the exposed name of a server's tool
+func (n *NamingPolicy) ToolName(serverName, prefix, originalName string) string {
+	if renamed, exists := n.renames[serverName][originalName]; exists {
+		return renamed
+	}
+	return n.exposedName(prefix, originalName)
+}
+
+// PromptName returns the exposed name of a server's prompt. Rename maps hold
+// tool names only and do not apply to prompts.
+func (n *NamingPolicy) PromptName(prefix, originalName string) string {
+	return n.exposedName(prefix, originalName)
+}
+
+func (n *NamingPolicy) exposedName(prefix, originalName string) string {
+	name := originalName
+	if !n.noPrefix {
+		name = prefix + n.separator + originalName
+	}
+	return n.shorten(name)
+}
+
+// shorten truncates names over the length limit and appends a hash of the
+// full name, so shortened names are deterministic and stay distinct. The cut
+// backs off to a rune boundary, so the result may be a few bytes shorter.
+func (n *NamingPolicy) shorten(name string) string {
+	if n.maxLength <= 0 || len(name) <= n.maxLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := n.separator + hex.EncodeToString(sum[:])[:config.NameHashLength]
+	cut := n.maxLength - len(suffix)
+	if cut < 0 {
+		cut = 0
+	}
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return name[:cut] + suffix
+}
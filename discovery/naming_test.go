@@ -0,0 +1,105 @@
+package discovery
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"mcp-debug/config"
+)
+
+func TestToolName(t *testing.T) {
+	cfg := &config.ProxyConfig{
+		Naming: config.NamingConfig{Separator: "__"},
+		Servers: []config.ServerConfig{
+			{Name: "database", Prefix: "db", Rename: map[string]string{"run_query": "sql"}},
+		},
+	}
+	policy := NewNamingPolicy(cfg)
+
+	tests := []struct {
+		name       string
+		serverName string
+		prefix     string
+		original   string
+		want       string
+	}{
+		{"prefixed", "database", "db", "list_tables", "db__list_tables"},
+		{"renamed", "database", "db", "run_query", "sql"},
+		{"rename of another server", "other", "ot", "run_query", "ot__run_query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.ToolName(tt.serverName, tt.prefix, tt.original); got != tt.want {
+				t.Errorf("ToolName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptNameIgnoresRenames(t *testing.T) {
+	policy := NewNamingPolicy(&config.ProxyConfig{
+		Servers: []config.ServerConfig{
+			{Name: "database", Prefix: "db", Rename: map[string]string{"run_query": "sql"}},
+		},
+	})
+	if got := policy.PromptName("db", "run_query"); got != "db_run_query" {
+		t.Errorf("PromptName() = %q, want %q", got, "db_run_query")
+	}
+}
+
+func TestNoPrefix(t *testing.T) {
+	policy := NewNamingPolicy(&config.ProxyConfig{Naming: config.NamingConfig{NoPrefix: true}})
+	if got := policy.ToolName("database", "db", "query"); got != "query" {
+		t.Errorf("ToolName() = %q, want %q", got, "query")
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		name      string
+		separator string
+		maxLength int
+		input     string
+	}{
+		{"short name", "_", 64, "db_query"},
+		{"exact length", "_", 16, strings.Repeat("a", 16)},
+		{"long ascii", "_", 16, strings.Repeat("a", 40)},
+		{"long multibyte", "_", 20, strings.Repeat("é", 30)},
+		{"long multibyte odd cut", "_", 21, "x" + strings.Repeat("é", 30)},
+		{"separator longer than limit", strings.Repeat("_", 20), 16, strings.Repeat("a", 40)},
+		{"no limit", "_", 0, strings.Repeat("a", 200)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &NamingPolicy{separator: tt.separator, maxLength: tt.maxLength}
+			got := policy.shorten(tt.input)
+
+			if !utf8.ValidString(got) {
+				t.Errorf("shorten() = %q, not valid UTF-8", got)
+			}
+			if tt.maxLength <= 0 || len(tt.input) <= tt.maxLength {
+				if got != tt.input {
+					t.Errorf("shorten() = %q, want unchanged %q", got, tt.input)
+				}
+				return
+			}
+			suffix := len(tt.separator) + config.NameHashLength
+			if len(got) > tt.maxLength && len(got) != suffix {
+				t.Errorf("shorten() = %q, %d bytes over limit %d", got, len(got), tt.maxLength)
+			}
+			if got2 := policy.shorten(tt.input); got2 != got {
+				t.Errorf("shorten() not deterministic: %q != %q", got, got2)
+			}
+		})
+	}
+}
+
+func TestShortenKeepsNamesDistinct(t *testing.T) {
+	policy := &NamingPolicy{separator: "_", maxLength: 16}
+	a := policy.shorten(strings.Repeat("a", 30) + "1")
+	b := policy.shorten(strings.Repeat("a", 30) + "2")
+	if a == b {
+		t.Errorf("shorten() gave %q for two different names", a)
+	}
+}
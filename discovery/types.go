@@ -3,6 +3,9 @@ package discovery
 import (
 	"encoding/json"
 	"time"
+	
+	"mcp-debug/client"
+	"mcp-debug/config"
 )
 
 // DiscoveryResult represents the result of discovering tools from a server
@@ -10,6 +13,7 @@ type DiscoveryResult struct {
 	ServerName   string        `json:"serverName"`
 	ServerPrefix string        `json:"serverPrefix"`
 	Tools        []RemoteTool  `json:"tools"`
+	HiddenTools  []string      `json:"hiddenTools,omitempty"` // original names of filtered-out tools
 	Error        error         `json:"error,omitempty"`
 	Duration     time.Duration `json:"duration"`
 }
@@ -22,6 +26,23 @@ type RemoteTool struct {
 	InputSchema  json.RawMessage `json:"inputSchema"`
 	ServerName   string          `json:"serverName"`
 	ServerPrefix string          `json:"serverPrefix"`
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+	Annotations  *client.ToolAnnotations `json:"annotations,omitempty"`
+	
+	// ArgumentRules rewrite the arguments of calls to this tool
+	ArgumentRules *config.ArgumentRules `json:"-"`
+	
+	// TransformRules shape the text of this tool's results
+	TransformRules *config.TransformRules `json:"-"`
+	
+	// CacheTTL is how long results of this tool are cached, 0 if they are not
+	CacheTTL time.Duration `json:"-"`
+	
+	// InputValidation is the mode for checking arguments against InputSchema
+	InputValidation string `json:"-"`
+	
+	// OutputValidation is the mode for checking results against OutputSchema
+	OutputValidation string `json:"-"`
 }
 
 // IsSuccessful returns true if the discovery was successful
@@ -34,23 +55,65 @@ func (r *DiscoveryResult) ToolCount() int {
 	return len(r.Tools)
 }
 
-// CreatePrefixedTool creates a RemoteTool with proper prefixing
-func CreatePrefixedTool(serverName, serverPrefix string, originalTool ToolInfo) RemoteTool {
-	prefixedName := serverPrefix + "_" + originalTool.Name
+// CreatePrefixedTool creates a RemoteTool named according to the naming policy
+func CreatePrefixedTool(naming *NamingPolicy, serverName, serverPrefix string, originalTool ToolInfo) RemoteTool {
+	prefixedName := naming.ToolName(serverName, serverPrefix, originalTool.Name)
 	
 	return RemoteTool{
 		OriginalName: originalTool.Name,
 		PrefixedName: prefixedName,
 		Description:  originalTool.Description,
 		InputSchema:  originalTool.InputSchema,
+		OutputSchema: originalTool.OutputSchema,
 		ServerName:   serverName,
 		ServerPrefix: serverPrefix,
+		Annotations:  originalTool.Annotations,
 	}
 }
 
+// RemotePrompt represents a prompt discovered from a remote server
+type RemotePrompt struct {
+	OriginalName string           `json:"originalName"`
+	PrefixedName string           `json:"prefixedName"`
+	Description  string           `json:"description"`
+	Arguments    []PromptArgument `json:"arguments,omitempty"`
+	ServerName   string           `json:"serverName"`
+	ServerPrefix string           `json:"serverPrefix"`
+}
+
+// PromptArgument describes an argument accepted by a remote prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// CreatePrefixedPrompt creates a RemotePrompt named according to the naming policy
+func CreatePrefixedPrompt(naming *NamingPolicy, serverName, serverPrefix string, originalPrompt PromptInfo) RemotePrompt {
+	prefixedName := naming.PromptName(serverPrefix, originalPrompt.Name)
+	
+	return RemotePrompt{
+		OriginalName: originalPrompt.Name,
+		PrefixedName: prefixedName,
+		Description:  originalPrompt.Description,
+		Arguments:    originalPrompt.Arguments,
+		ServerName:   serverName,
+		ServerPrefix: serverPrefix,
+	}
+}
+
+// PromptInfo represents prompt information from the MCP client
+type PromptInfo struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
 // ToolInfo represents tool information from the MCP client
 type ToolInfo struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	InputSchema json.RawMessage `json:"inputSchema"`
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description"`
+	InputSchema  json.RawMessage         `json:"inputSchema"`
+	OutputSchema json.RawMessage         `json:"outputSchema,omitempty"`
+	Annotations  *client.ToolAnnotations `json:"annotations,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,450 @@
+package fuzz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"mcp-debug/client"
+	"mcp-debug/integration"
+)
+
+// FailureKind classifies what went wrong with a call
+type FailureKind string
+
+const (
+	FailureCrash          FailureKind = "crash"           // the server exited
+	FailureHang           FailureKind = "hang"            // no response within the timeout
+	FailureProtocolError  FailureKind = "protocol_error"  // JSON-RPC error or malformed result
+	FailureUnflaggedError FailureKind = "unflagged_error" // error text without isError
+)
+
+// codeInvalidParams is the JSON-RPC error a server may answer bad arguments with
+const codeInvalidParams = -32602
+
+// maxShrinkAttempts bounds the calls spent minimizing one failure. Hangs
+// cost a timeout per attempt, so they get fewer.
+const (
+	maxShrinkAttempts     = 32
+	maxHangShrinkAttempts = 4
+)
+
+// stderrTail is how much of the server's stderr is kept for crash reports
+const stderrTail = 4096
+
+// errorText matches result text that reports a failure
+var errorText = regexp.MustCompile(`(?i)^\s*(error|exception|fatal)\b|traceback \(most recent call last\)|panic: |unhandled exception|stack trace`)
+
+// Options configures a fuzzing run
+type Options struct {
+	Command  []string      // server command and its arguments
+	Timeout  time.Duration // how long to wait for each call
+	Tools    []string      // tools to fuzz, all if empty
+	OutDir   string        // where recordings of crashes and hangs are saved
+	ReadOnly bool          // only fuzz tools annotated as read-only
+}
+
+// failure is the outcome of a call that went wrong
+type failure struct {
+	kind    FailureKind
+	message string
+	stderr  string
+}
+
+// Fuzzer calls a server's tools with generated arguments, restarting the
+// server whenever it exits or hangs
+type Fuzzer struct {
+	opts   Options
+	client *client.StdioClient
+	stderr *tailBuffer
+	saved  int
+}
+
+// Run fuzzes the server's tools. It fails only if the server cannot be
+// started or its tools cannot be listed.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	f := &Fuzzer{opts: opts}
+	defer f.stop()
+
+	if err := f.start(ctx); err != nil {
+		return nil, err
+	}
+	tools, err := f.client.ListTools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	selected, err := f.selectTools(tools)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Command: opts.Command}
+	seen := make(map[string]bool)
+	for _, tool := range tools {
+		reason, included := selected[tool.Name]
+		if !included {
+			continue
+		}
+		result := ToolResult{Name: tool.Name}
+		if reason != "" {
+			result.Skipped = reason
+			report.Tools = append(report.Tools, result)
+			continue
+		}
+
+		for _, c := range Cases(tool.InputSchema) {
+			if ctx.Err() != nil {
+				return report, nil
+			}
+			result.Cases++
+			report.Calls++
+
+			fail := f.try(ctx, tool.Name, c.Args)
+			if fail == nil {
+				continue
+			}
+
+			args, calls := f.minimize(ctx, tool.Name, c.Args, fail.kind)
+			report.Calls += calls
+			key, _ := json.Marshal(args)
+			if seen[tool.Name+string(fail.kind)+string(key)] {
+				continue
+			}
+			seen[tool.Name+string(fail.kind)+string(key)] = true
+
+			finding := Finding{
+				Tool:    tool.Name,
+				Kind:    fail.kind,
+				Case:    c.Name,
+				Message: fail.message,
+				Args:    args,
+				Stderr:  fail.stderr,
+			}
+			if fail.kind == FailureCrash || fail.kind == FailureHang {
+				path, err := f.saveRecording(tool.Name, fail.kind, args)
+				if err != nil {
+					finding.Message += fmt.Sprintf(" (recording not saved: %v)", err)
+				}
+				finding.Recording = path
+			}
+			report.Findings = append(report.Findings, finding)
+			result.Findings++
+		}
+		report.Tools = append(report.Tools, result)
+	}
+	return report, nil
+}
+
+// selectTools maps the name of each tool to fuzz to "", and of each
+// excluded tool to the reason
+func (f *Fuzzer) selectTools(tools []client.ToolInfo) (map[string]string, error) {
+	wanted := make(map[string]bool)
+	for _, name := range f.opts.Tools {
+		wanted[name] = true
+	}
+
+	selected := make(map[string]string)
+	for _, tool := range tools {
+		if len(wanted) > 0 && !wanted[tool.Name] {
+			continue
+		}
+		delete(wanted, tool.Name)
+
+		if f.opts.ReadOnly && !tool.Annotations.IsReadOnly() {
+			selected[tool.Name] = "not annotated as read-only"
+			continue
+		}
+		selected[tool.Name] = ""
+	}
+
+	if len(wanted) > 0 {
+		var missing []string
+		for name := range wanted {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("unknown tools: %s", strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// start launches and initializes the server
+func (f *Fuzzer) start(ctx context.Context) error {
+	command := f.opts.Command
+	f.stderr = &tailBuffer{}
+	f.client = client.NewStdioClient("fuzz", command[0], command[1:])
+	f.client.SetStderr(f.stderr)
+
+	if err := f.client.Connect(context.Background()); err != nil {
+		return err
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, f.opts.Timeout)
+	defer cancel()
+	if _, err := f.client.Initialize(initCtx); err != nil {
+		f.stop()
+		return err
+	}
+	return f.client.SendNotification("notifications/initialized", nil)
+}
+
+// stop kills the server
+func (f *Fuzzer) stop() {
+	if f.client != nil {
+		f.client.Close()
+		f.client = nil
+	}
+}
+
+// try calls a tool, restarting the server first if needed, and returns
+// what went wrong, if anything. A ping after the call catches servers that
+// exit or wedge right after answering.
+func (f *Fuzzer) try(ctx context.Context, toolName string, args map[string]interface{}) *failure {
+	if f.client == nil || f.client.Exited() {
+		f.stop()
+		if err := f.start(ctx); err != nil {
+			return &failure{kind: FailureCrash, message: fmt.Sprintf("server failed to restart: %v", err), stderr: f.stderr.String()}
+		}
+	}
+
+	response, fail := f.send(ctx, "tools/call", client.CallToolParams{Name: toolName, Arguments: args})
+	if fail != nil {
+		return fail
+	}
+	if _, fail := f.send(ctx, "ping", nil); fail != nil {
+		fail.message = "after answering: " + fail.message
+		return fail
+	}
+
+	if response.Error != nil {
+		if response.Error.Code == codeInvalidParams {
+			return nil
+		}
+		return &failure{kind: FailureProtocolError, message: fmt.Sprintf("JSON-RPC error %d: %s", response.Error.Code, response.Error.Message)}
+	}
+
+	var result struct {
+		Content []client.ContentItem `json:"content"`
+		IsError bool                 `json:"isError"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return &failure{kind: FailureProtocolError, message: fmt.Sprintf("invalid result: %v", err)}
+	}
+	if result.Content == nil {
+		return &failure{kind: FailureProtocolError, message: "result has no content array"}
+	}
+	if !result.IsError {
+		for _, content := range result.Content {
+			if content.Type == "text" && errorText.MatchString(content.Text) {
+				return &failure{kind: FailureUnflaggedError, message: "result reports an error without isError: " + truncate(content.Text, 200)}
+			}
+		}
+	}
+	return nil
+}
+
+// send sends a request and turns a missing response into a failure. A hung
+// server is killed so the next call starts a fresh one.
+func (f *Fuzzer) send(ctx context.Context, method string, params interface{}) (*client.JSONRPCResponse, *failure) {
+	callCtx, cancel := context.WithTimeout(ctx, f.opts.Timeout)
+	defer cancel()
+
+	response, err := f.client.SendRequest(callCtx, f.client.NewRequest(method, params))
+	if err == nil {
+		return response, nil
+	}
+
+	switch {
+	case f.client.Exited():
+		// Let the process finish writing its last words
+		time.Sleep(50 * time.Millisecond)
+		return nil, &failure{kind: FailureCrash, message: "server exited", stderr: f.stderr.String()}
+	case errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+		f.stop()
+		return nil, &failure{kind: FailureHang, message: fmt.Sprintf("no response to %s within %v", method, f.opts.Timeout), stderr: f.stderr.String()}
+	default:
+		return nil, &failure{kind: FailureProtocolError, message: err.Error()}
+	}
+}
+
+// minimize shrinks failing arguments while the failure reproduces: it
+// drops arguments, then halves strings and arrays. Returns the smallest
+// arguments found and the number of calls spent.
+func (f *Fuzzer) minimize(ctx context.Context, toolName string, args map[string]interface{}, kind FailureKind) (map[string]interface{}, int) {
+	budget := maxShrinkAttempts
+	if kind == FailureHang {
+		budget = maxHangShrinkAttempts
+	}
+
+	calls := 0
+	reproduces := func(candidate map[string]interface{}) bool {
+		if calls >= budget || ctx.Err() != nil {
+			return false
+		}
+		calls++
+		fail := f.try(ctx, toolName, candidate)
+		return fail != nil && fail.kind == kind
+	}
+
+	current := args
+	for _, name := range sortedKeys(current) {
+		if candidate := without(current, name); reproduces(candidate) {
+			current = candidate
+		}
+	}
+	for _, name := range sortedKeys(current) {
+		for {
+			smaller, ok := shrink(current[name])
+			if !ok {
+				break
+			}
+			candidate := with(current, name, smaller)
+			if !reproduces(candidate) {
+				break
+			}
+			current = candidate
+		}
+	}
+	return current, calls
+}
+
+// shrink halves a string or array
+func shrink(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		runes := []rune(v)
+		if len(runes) <= 1 {
+			return nil, false
+		}
+		return string(runes[:len(runes)/2]), true
+	case []interface{}:
+		if len(v) <= 1 {
+			return nil, false
+		}
+		return v[:len(v)/2], true
+	}
+	return nil, false
+}
+
+// saveRecording writes the session leading to a failure in the recording
+// format, so it can be replayed with --playback-client
+func (f *Fuzzer) saveRecording(toolName string, kind FailureKind, args map[string]interface{}) (string, error) {
+	if err := os.MkdirAll(f.opts.OutDir, 0755); err != nil {
+		return "", err
+	}
+	f.saved++
+	path := filepath.Join(f.opts.OutDir, fmt.Sprintf("%s-%s-%d.jsonl", safeName(toolName), kind, f.saved))
+
+	now := time.Now()
+	session := integration.RecordingSession{
+		StartTime:  now,
+		ServerInfo: "mcp-debug fuzz: " + strings.Join(f.opts.Command, " "),
+		Messages:   []integration.RecordedMessage{},
+	}
+	requests := []struct {
+		messageType string
+		message     interface{}
+	}{
+		{"initialize", client.JSONRPCRequest{
+			JSONRPC: "2.0",
+			Method:  "initialize",
+			Params: client.InitializeParams{
+				ProtocolVersion: "2024-11-05",
+				Capabilities:    map[string]interface{}{},
+				ClientInfo:      client.ClientInfo{Name: "mcp-debug-fuzz", Version: "1.0.0"},
+			},
+			ID: 1,
+		}},
+		{"notification", client.JSONRPCNotification{JSONRPC: "2.0", Method: "notifications/initialized"}},
+		{"tool_call", client.JSONRPCRequest{
+			JSONRPC: "2.0",
+			Method:  "tools/call",
+			Params:  client.CallToolParams{Name: toolName, Arguments: args},
+			ID:      2,
+		}},
+	}
+
+	var lines []string
+	header, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	lines = append(lines, "# mcp-debug fuzz "+string(kind)+" in "+toolName, string(header))
+	for _, request := range requests {
+		message, err := json.Marshal(request.message)
+		if err != nil {
+			return "", err
+		}
+		recorded, err := json.Marshal(integration.RecordedMessage{
+			Timestamp:   now,
+			Direction:   "request",
+			MessageType: request.messageType,
+			ToolName:    toolName,
+			Message:     message,
+		})
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, string(recorded))
+	}
+
+	return path, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+func safeName(name string) string {
+	return unsafeChars.ReplaceAllString(name, "_")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// truncate shortens text for display
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max] + "..."
+}
+
+// tailBuffer keeps the last stderrTail bytes written to it
+type tailBuffer struct {
+	data []byte
+	mu   sync.Mutex
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if len(b.data) > stderrTail {
+		b.data = b.data[len(b.data)-stderrTail:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.TrimSpace(string(b.data))
+}
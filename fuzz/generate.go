@@ -0,0 +1,350 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// CaseKind says whether a case's arguments match the tool's schema
+type CaseKind string
+
+const (
+	KindValid    CaseKind = "valid"    // typical arguments
+	KindBoundary CaseKind = "boundary" // allowed by the schema, but extreme
+	KindInvalid  CaseKind = "invalid"  // rejected by the schema
+)
+
+// hugeStringLength is the length of the oversized string argument
+const hugeStringLength = 1 << 20
+
+// largeArrayLength is the length of the oversized array argument
+const largeArrayLength = 1000
+
+// unicodeString mixes scripts, emoji, direction overrides, control
+// characters and quoting that naive string handling trips over
+const unicodeString = "ünïcödé ✓ 日本語 🚀 \u202eRTL\u202c \u0000\u0007\t\r\n \"quoted\" \\ <tag> ' OR 1=1 --"
+
+// Case is one set of arguments to call a tool with
+type Case struct {
+	Name string                 `json:"name"`
+	Kind CaseKind               `json:"kind"`
+	Args map[string]interface{} `json:"args"`
+}
+
+// Cases generates valid, boundary and invalid arguments from a tool's input
+// schema. Only top-level properties are varied; nested values are valid.
+func Cases(inputSchema json.RawMessage) []Case {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(inputSchema, &schema); err != nil || schema == nil {
+		schema = map[string]interface{}{}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := stringList(schema["required"])
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	minimal := validObject(schema, false)
+	full := validObject(schema, true)
+
+	var cases []Case
+	seen := make(map[string]bool)
+	add := func(name string, kind CaseKind, args map[string]interface{}) {
+		key, _ := json.Marshal(args)
+		if seen[string(key)] {
+			return
+		}
+		seen[string(key)] = true
+		cases = append(cases, Case{Name: name, Kind: kind, Args: args})
+	}
+
+	add("required arguments", KindValid, minimal)
+	add("all arguments", KindValid, full)
+
+	for _, name := range names {
+		propSchema, _ := properties[name].(map[string]interface{})
+		for _, value := range boundaryValues(propSchema) {
+			add(fmt.Sprintf("%s: %s", name, value.label), KindBoundary, with(full, name, value.value))
+		}
+		for _, value := range invalidValues(propSchema) {
+			add(fmt.Sprintf("%s: %s", name, value.label), KindInvalid, with(full, name, value.value))
+		}
+	}
+
+	for _, name := range required {
+		add("missing "+name, KindInvalid, without(full, name))
+	}
+	if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+		add("unknown property", KindInvalid, with(full, "mcp_debug_unknown", "x"))
+	}
+	return cases
+}
+
+type labeledValue struct {
+	label string
+	value interface{}
+}
+
+// boundaryValues returns values allowed by a schema that sit at its edges
+func boundaryValues(schema map[string]interface{}) []labeledValue {
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 1 {
+		return []labeledValue{{"last enum value", enum[len(enum)-1]}}
+	}
+
+	var values []labeledValue
+	switch schemaType(schema) {
+	case "string":
+		values = append(values,
+			labeledValue{"empty string", ""},
+			labeledValue{"unicode and special characters", unicodeString},
+			labeledValue{"huge string", strings.Repeat("A", hugeStringLength)})
+		if n, ok := length(schema["minLength"]); ok && n > 0 {
+			values = append(values, labeledValue{"minLength", strings.Repeat("a", n)})
+		}
+		if n, ok := length(schema["maxLength"]); ok {
+			values = append(values, labeledValue{"maxLength", strings.Repeat("a", n)})
+		}
+	case "integer":
+		values = append(values,
+			labeledValue{"zero", 0},
+			labeledValue{"negative", -1},
+			labeledValue{"largest safe integer", int64(1<<53 - 1)},
+			labeledValue{"smallest safe integer", -int64(1<<53 - 1)})
+		values = append(values, limits(schema)...)
+	case "number":
+		values = append(values,
+			labeledValue{"zero", 0},
+			labeledValue{"negative fraction", -1.5},
+			labeledValue{"huge number", 1e308},
+			labeledValue{"huge negative number", -1e308})
+		values = append(values, limits(schema)...)
+	case "boolean":
+		values = append(values, labeledValue{"false", false})
+	case "array":
+		item := validValue(mapValue(schema["items"]))
+		large := make([]interface{}, largeArrayLength)
+		for i := range large {
+			large[i] = item
+		}
+		values = append(values,
+			labeledValue{"empty array", []interface{}{}},
+			labeledValue{"large array", large})
+	case "object":
+		values = append(values, labeledValue{"empty object", map[string]interface{}{}})
+	}
+	return values
+}
+
+// limits returns a numeric schema's minimum and maximum
+func limits(schema map[string]interface{}) []labeledValue {
+	var values []labeledValue
+	if n, ok := number(schema["minimum"]); ok {
+		values = append(values, labeledValue{"minimum", n})
+	}
+	if n, ok := number(schema["maximum"]); ok {
+		values = append(values, labeledValue{"maximum", n})
+	}
+	return values
+}
+
+// invalidValues returns values a schema rejects
+func invalidValues(schema map[string]interface{}) []labeledValue {
+	var values []labeledValue
+	if !nullable(schema) {
+		values = append(values, labeledValue{"null", nil})
+	}
+
+	switch schemaType(schema) {
+	case "string":
+		values = append(values, labeledValue{"wrong type", 12345})
+		if _, ok := schema["enum"]; ok {
+			values = append(values, labeledValue{"not in enum", "mcp-debug-not-in-enum"})
+		}
+		if n, ok := length(schema["minLength"]); ok && n > 0 {
+			values = append(values, labeledValue{"below minLength", strings.Repeat("a", n-1)})
+		}
+		if n, ok := length(schema["maxLength"]); ok {
+			values = append(values, labeledValue{"above maxLength", strings.Repeat("a", n+1)})
+		}
+	case "integer", "number":
+		values = append(values, labeledValue{"wrong type", "not a number"})
+		if schemaType(schema) == "integer" {
+			values = append(values, labeledValue{"fraction", 1.5})
+		}
+		if n, ok := number(schema["minimum"]); ok {
+			values = append(values, labeledValue{"below minimum", n - 1})
+		}
+		if n, ok := number(schema["maximum"]); ok {
+			values = append(values, labeledValue{"above maximum", n + 1})
+		}
+	case "boolean":
+		values = append(values, labeledValue{"wrong type", "true"})
+	case "array":
+		values = append(values, labeledValue{"wrong type", "not an array"})
+	case "object":
+		values = append(values, labeledValue{"wrong type", "not an object"})
+	default:
+		return nil
+	}
+	return values
+}
+
+// validValue returns a value a schema accepts
+func validValue(schema map[string]interface{}) interface{} {
+	if value, ok := schema["const"]; ok {
+		return value
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if value, ok := schema["default"]; ok {
+		return value
+	}
+	for _, keyword := range []string{"anyOf", "oneOf", "allOf"} {
+		if options, ok := schema[keyword].([]interface{}); ok && len(options) > 0 {
+			return validValue(mapValue(options[0]))
+		}
+	}
+
+	switch schemaType(schema) {
+	case "string":
+		if n, ok := number(schema["minLength"]); ok && n > 0 && n == math.Trunc(n) {
+			return strings.Repeat("a", int(math.Min(n, hugeStringLength)))
+		}
+		return "test"
+	case "integer", "number":
+		if n, ok := number(schema["minimum"]); ok {
+			return n
+		}
+		if n, ok := number(schema["maximum"]); ok && n < 1 {
+			return n
+		}
+		return 1
+	case "boolean":
+		return true
+	case "array":
+		count := 1
+		if n, ok := number(schema["minItems"]); ok && n > 1 {
+			count = int(math.Min(n, largeArrayLength))
+		}
+		if n, ok := number(schema["maxItems"]); ok && n == 0 {
+			count = 0
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = validValue(mapValue(schema["items"]))
+		}
+		return items
+	case "object":
+		return validObject(schema, false)
+	case "null":
+		return nil
+	default:
+		return "test"
+	}
+}
+
+// validObject returns valid values for an object schema's required
+// properties, or for all of them
+func validObject(schema map[string]interface{}, all bool) map[string]interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	object := make(map[string]interface{})
+	if all {
+		for name, propSchema := range properties {
+			object[name] = validValue(mapValue(propSchema))
+		}
+		return object
+	}
+	for _, name := range stringList(schema["required"]) {
+		object[name] = validValue(mapValue(properties[name]))
+	}
+	return object
+}
+
+// schemaType returns a schema's type, the first non-null one if it lists several
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, option := range t {
+			if name, ok := option.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// nullable returns true if a schema's type list includes null
+func nullable(schema map[string]interface{}) bool {
+	types, _ := schema["type"].([]interface{})
+	for _, option := range types {
+		if option == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func mapValue(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
+
+func number(value interface{}) (float64, bool) {
+	n, ok := value.(float64)
+	return n, ok
+}
+
+// length reads a string length keyword. Values that are not non-negative
+// integers are ignored, as are lengths beyond hugeStringLength, which the
+// huge string case already covers.
+func length(value interface{}) (int, bool) {
+	n, ok := number(value)
+	if !ok || n < 0 || n != math.Trunc(n) || n > hugeStringLength {
+		return 0, false
+	}
+	return int(n), true
+}
+
+func stringList(value interface{}) []string {
+	var list []string
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// with returns a copy of args with one argument set
+func with(args map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(args)+1)
+	for k, v := range args {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
+// without returns a copy of args with one argument removed
+func without(args map[string]interface{}, name string) map[string]interface{} {
+	result := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if k != name {
+			result[k] = v
+		}
+	}
+	return result
+}
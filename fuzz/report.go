@@ -0,0 +1,74 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Finding is a failure with the smallest arguments found to reproduce it
+type Finding struct {
+	Tool      string                 `json:"tool"`
+	Kind      FailureKind            `json:"kind"`
+	Case      string                 `json:"case"`
+	Message   string                 `json:"message"`
+	Args      map[string]interface{} `json:"args"`
+	Stderr    string                 `json:"stderr,omitempty"`
+	Recording string                 `json:"recording,omitempty"`
+}
+
+// ToolResult counts the cases run against a tool
+type ToolResult struct {
+	Name     string `json:"name"`
+	Cases    int    `json:"cases"`
+	Findings int    `json:"findings"`
+	Skipped  string `json:"skipped,omitempty"`
+}
+
+// Report is the result of a fuzzing run
+type Report struct {
+	Command  []string     `json:"command"`
+	Calls    int          `json:"calls"`
+	Tools    []ToolResult `json:"tools"`
+	Findings []Finding    `json:"findings"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report for reading in a terminal
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Fuzzing report for %s\n\n", strings.Join(r.Command, " "))
+
+	for _, tool := range r.Tools {
+		if tool.Skipped != "" {
+			fmt.Fprintf(w, "SKIP  %s (%s)\n", tool.Name, tool.Skipped)
+			continue
+		}
+		status := "OK"
+		if tool.Findings > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%-4s  %s: %d cases, %d findings\n", status, tool.Name, tool.Cases, tool.Findings)
+	}
+
+	for _, finding := range r.Findings {
+		args, _ := json.Marshal(finding.Args)
+		fmt.Fprintf(w, "\n%s in %s (case %q)\n", strings.ToUpper(string(finding.Kind)), finding.Tool, finding.Case)
+		fmt.Fprintf(w, "  %s\n", finding.Message)
+		fmt.Fprintf(w, "  args: %s\n", truncate(string(args), 500))
+		if finding.Recording != "" {
+			fmt.Fprintf(w, "  recording: %s\n", finding.Recording)
+		}
+		if finding.Stderr != "" {
+			fmt.Fprintf(w, "  stderr:\n    %s\n", strings.ReplaceAll(truncate(finding.Stderr, 2000), "\n", "\n    "))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d findings in %d calls\n", len(r.Findings), r.Calls)
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mcp-debug/client"
+)
+
+// registerCompletionHandlers advertises the completions capability and routes
+// completion/complete to the server owning the referenced prompt or template
+func (p *ProxyServer) registerCompletionHandlers(t *stdioTransport) {
+	t.AdvertiseCapability("completions", struct{}{})
+	t.Handle("completion/complete", p.handleComplete)
+}
+
+func (p *ProxyServer) handleComplete(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var request client.CompleteParams
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, newRPCError(errCodeInvalidParams, "invalid completion params: %v", err)
+	}
+
+	// Rewrite the proxy-side reference to the name or URI template the owning server knows
+	var serverName string
+	var mcpClient client.MCPClient
+	switch request.Ref.Type {
+	case client.CompletionRefPrompt:
+		prompt, promptClient, exists := p.prompts.GetPrompt(request.Ref.Name)
+		if !exists {
+			return nil, newRPCError(errCodeInvalidParams, "prompt '%s' not found", request.Ref.Name)
+		}
+		serverName, mcpClient = prompt.ServerName, promptClient
+		request.Ref.Name = prompt.OriginalName
+
+	case client.CompletionRefResource:
+		name, resourceClient, originalURI, err := p.resources.Resolve(request.Ref.URI)
+		if err != nil {
+			return nil, newRPCError(errCodeInvalidParams, "%v", err)
+		}
+		serverName, mcpClient = name, resourceClient
+		request.Ref.URI = originalURI
+
+	default:
+		return nil, newRPCError(errCodeInvalidParams, "unsupported completion reference type '%s'", request.Ref.Type)
+	}
+
+	// Servers without completion support simply have no suggestions
+	if mcpClient == nil || !mcpClient.IsConnected() || !client.HasCapability(mcpClient.ServerCapabilities(), "completions") {
+		return emptyCompletion(), nil
+	}
+
+	result, err := mcpClient.Complete(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] %v", serverName, err)
+	}
+	if result.Completion.Values == nil {
+		result.Completion.Values = []string{}
+	}
+	return result, nil
+}
+
+// emptyCompletion returns a completion result with no values
+func emptyCompletion() *client.CompleteResult {
+	return &client.CompleteResult{Completion: client.CompletionValues{Values: []string{}}}
+}
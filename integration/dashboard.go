@@ -0,0 +1,178 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-debug/config"
+	"mcp-debug/dashboard"
+	"mcp-debug/redact"
+)
+
+// dashboardBackend shows the wrapper's servers and tools on the dashboard and
+// runs its buttons through the server management tools
+type dashboardBackend struct {
+	w *DynamicWrapper
+}
+
+func (b *dashboardBackend) Servers() []dashboard.Server {
+	w := b.w
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	toolCounts := make(map[string]int)
+	for _, tool := range w.proxyServer.registry.GetAllTools() {
+		toolCounts[tool.ServerName]++
+	}
+
+	clients := w.proxyServer.clientList()
+	servers := make([]dashboard.Server, 0, len(w.proxyServer.config.Servers)+len(w.dynamicServers))
+	for _, serverConfig := range w.proxyServer.config.Servers {
+		connected := false
+		for _, c := range clients {
+			if c.ServerName() == serverConfig.Name {
+				connected = c.IsConnected()
+			}
+		}
+		servers = append(servers, b.server(serverConfig, true, connected, "", toolCounts))
+	}
+
+	names := make([]string, 0, len(w.dynamicServers))
+	for name := range w.dynamicServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		info := w.dynamicServers[name]
+		errorMessage := ""
+		if !info.IsConnected {
+			errorMessage = info.ErrorMessage
+		}
+		servers = append(servers, b.server(info.Config, false, info.IsConnected, errorMessage, toolCounts))
+	}
+	return servers
+}
+
+func (b *dashboardBackend) server(serverConfig config.ServerConfig, static, connected bool, errorMessage string, toolCounts map[string]int) dashboard.Server {
+	registry := b.w.proxyServer.registry
+	return dashboard.Server{
+		Name:      serverConfig.Name,
+		Static:    static,
+		Connected: connected,
+		Error:     redact.Default.String(errorMessage),
+		Command:   redact.Default.String(serverCommand(serverConfig)),
+		Tools:     toolCounts[serverConfig.Name],
+		Circuit:   registry.GetBreaker(serverConfig.Name).Status(),
+		Chaos:     registry.GetChaos(serverConfig.Name).Status(),
+	}
+}
+
+// serverCommand returns the command line of a stdio server, or the URL of an
+// HTTP one
+func serverCommand(serverConfig config.ServerConfig) string {
+	if serverConfig.Command == "" {
+		return serverConfig.URL
+	}
+	return strings.Join(append([]string{serverConfig.Command}, serverConfig.Args...), " ")
+}
+
+func (b *dashboardBackend) Tools() []dashboard.Tool {
+	b.w.mu.RLock()
+	defer b.w.mu.RUnlock()
+
+	remoteTools := b.w.proxyServer.registry.GetAllTools()
+	tools := make([]dashboard.Tool, 0, len(remoteTools))
+	for _, tool := range remoteTools {
+		tools = append(tools, dashboard.Tool{
+			Name:         tool.PrefixedName,
+			Server:       tool.ServerName,
+			OriginalName: tool.OriginalName,
+			Description:  tool.Description,
+			InputSchema:  tool.InputSchema,
+			OutputSchema: tool.OutputSchema,
+		})
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+func (b *dashboardBackend) Disconnect(ctx context.Context, name string) (string, error) {
+	if _, _, err := b.dynamicServer(name); err != nil {
+		return "", err
+	}
+	return b.call(ctx, b.w.handleServerDisconnect, map[string]interface{}{"name": name})
+}
+
+// Reconnect starts a disconnected server again with its last command
+func (b *dashboardBackend) Reconnect(ctx context.Context, name string) (string, error) {
+	command, _, err := b.dynamicServer(name)
+	if err != nil {
+		return "", err
+	}
+	return b.call(ctx, b.w.handleServerReconnect, map[string]interface{}{"name": name, "command": command})
+}
+
+// Restart disconnects the server if needed and starts it with its last
+// command
+func (b *dashboardBackend) Restart(ctx context.Context, name string) (string, error) {
+	_, connected, err := b.dynamicServer(name)
+	if err != nil {
+		return "", err
+	}
+	if connected {
+		if _, err := b.Disconnect(ctx, name); err != nil {
+			return "", err
+		}
+	}
+	message, err := b.Reconnect(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	serverLog.Info("Restarted server from dashboard", "server", name)
+	return fmt.Sprintf("Restarted server '%s'.\n%s", name, message), nil
+}
+
+// dynamicServer returns the command line of a dynamic server and whether it
+// is connected. Static servers live as long as the proxy and cannot be
+// disconnected.
+func (b *dashboardBackend) dynamicServer(name string) (string, bool, error) {
+	b.w.mu.RLock()
+	defer b.w.mu.RUnlock()
+
+	info, exists := b.w.dynamicServers[name]
+	if !exists {
+		for _, serverConfig := range b.w.proxyServer.config.Servers {
+			if serverConfig.Name == name {
+				return "", false, fmt.Errorf("Server '%s' is a static server from the config and cannot be disconnected", name)
+			}
+		}
+		return "", false, fmt.Errorf("Server '%s' not found", name)
+	}
+	return serverCommand(info.Config), info.IsConnected, nil
+}
+
+// call runs a management tool handler, turning an error result into an error
+func (b *dashboardBackend) call(ctx context.Context, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), arguments map[string]interface{}) (string, error) {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = arguments
+
+	result, err := handler(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	var text string
+	if len(result.Content) > 0 {
+		if content, ok := mcp.AsTextContent(result.Content[0]); ok {
+			text = redact.Default.String(content.Text)
+		}
+	}
+	if result.IsError {
+		return "", errors.New(text)
+	}
+	return text, nil
+}
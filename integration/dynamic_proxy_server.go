@@ -3,7 +3,6 @@ package integration
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"sync"
 
@@ -11,6 +10,7 @@ import (
 	"github.com/metoro-io/mcp-golang/transport/stdio"
 	"mcp-debug/client"
 	"mcp-debug/config"
+	"mcp-debug/logging"
 )
 
 // DiscoveredTool represents a tool discovered from a remote server
@@ -54,7 +54,7 @@ func (p *DynamicProxyServer) ConnectToServer(ctx context.Context, serverConfig c
 	defer p.mu.Unlock()
 
 	serverName := serverConfig.Name
-	log.Printf("Connecting to server: %s", serverName)
+	serverLog.Info("Connecting to server", "server", serverName)
 
 	// Check if already connected
 	if _, exists := p.clients[serverName]; exists {
@@ -95,18 +95,18 @@ func (p *DynamicProxyServer) ConnectToServer(ctx context.Context, serverConfig c
 	toolCount := 0
 	for _, tool := range discoveredTools {
 		if err := p.registerTool(tool, mcpClient); err != nil {
-			log.Printf("Warning: Failed to register tool %s: %v", tool.PrefixedName, err)
+			serverLog.Warn("Failed to register tool", "server", serverName, "tool", tool.PrefixedName, "error", err)
 			continue
 		}
 		registeredTools = append(registeredTools, tool.PrefixedName)
 		toolCount++
-		log.Printf("Dynamically registered tool: %s", tool.PrefixedName)
+		serverLog.Debug("Dynamically registered tool", "server", serverName, "tool", tool.PrefixedName)
 	}
 	
 	// Track registered tools for this server
 	p.toolRegistry[serverName] = registeredTools
 
-	log.Printf("Successfully connected to %s and registered %d tools", serverName, toolCount)
+	serverLog.Info("Successfully connected to server", "server", serverName, "tools", toolCount)
 	return nil
 }
 
@@ -115,7 +115,7 @@ func (p *DynamicProxyServer) DisconnectFromServer(serverName string) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	log.Printf("Disconnecting from server: %s", serverName)
+	serverLog.Info("Disconnecting from server", "server", serverName)
 
 	// Get client
 	mcpClient, exists := p.clients[serverName]
@@ -128,10 +128,10 @@ func (p *DynamicProxyServer) DisconnectFromServer(serverName string) error {
 	if registeredTools, exists := p.toolRegistry[serverName]; exists {
 		for _, toolName := range registeredTools {
 			if err := p.mcpServer.DeregisterTool(toolName); err != nil {
-				log.Printf("Warning: Failed to deregister tool %s: %v", toolName, err)
+				serverLog.Warn("Failed to deregister tool", "server", serverName, "tool", toolName, "error", err)
 			} else {
 				toolsDeregistered++
-				log.Printf("Deregistered tool: %s", toolName)
+				serverLog.Debug("Deregistered tool", "server", serverName, "tool", toolName)
 			}
 		}
 		delete(p.toolRegistry, serverName)
@@ -139,14 +139,14 @@ func (p *DynamicProxyServer) DisconnectFromServer(serverName string) error {
 
 	// Close client connection
 	if err := mcpClient.Close(); err != nil {
-		log.Printf("Warning: Error closing client for %s: %v", serverName, err)
+		serverLog.Warn("Error closing client", "server", serverName, "error", err)
 	}
 
 	// Remove from maps
 	delete(p.clients, serverName)
 	delete(p.serverConfigs, serverName)
 
-	log.Printf("Successfully disconnected from %s and removed %d tools", serverName, toolsDeregistered)
+	serverLog.Info("Successfully disconnected from server", "server", serverName, "tools", toolsDeregistered)
 	return nil
 }
 
@@ -155,7 +155,7 @@ func (p *DynamicProxyServer) Serve() error {
 	// Register management tools
 	p.registerManagementTools()
 	
-	log.Printf("Starting dynamic MCP proxy server (tools will be added as servers connect)...")
+	proxyLog.Info("Starting dynamic MCP proxy server (tools will be added as servers connect)...")
 	return p.mcpServer.Serve()
 }
 
@@ -267,7 +267,7 @@ func (p *DynamicProxyServer) handleServerAdd(args interface{}) (*mcp_golang.Tool
 	go func() {
 		ctx := context.Background()
 		if err := p.ConnectToServer(ctx, serverConfig); err != nil {
-			log.Printf("Failed to connect to server %s: %v", addArgs.Name, err)
+			serverLog.Error("Failed to connect to server", "server", addArgs.Name, "error", err)
 		}
 	}()
 	
@@ -327,6 +327,7 @@ func (p *DynamicProxyServer) createAndConnectClient(ctx context.Context, serverC
 	switch serverConfig.Transport {
 	case "stdio":
 		stdioClient := client.NewStdioClient(serverConfig.Name, serverConfig.Command, serverConfig.Args)
+		stdioClient.SetStderr(logging.ServerStderr(serverConfig.Name))
 		if serverConfig.Env != nil {
 			// Convert map[string]string to []string
 			envSlice := make([]string, 0, len(serverConfig.Env))
@@ -424,12 +425,12 @@ func (p *DynamicProxyServer) Shutdown() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	log.Printf("Shutting down dynamic proxy server...")
+	proxyLog.Info("Shutting down dynamic proxy server...")
 
 	// Close all client connections
 	for serverName, mcpClient := range p.clients {
 		if err := mcpClient.Close(); err != nil {
-			log.Printf("Warning: Error closing client for %s: %v", serverName, err)
+			serverLog.Warn("Error closing client", "server", serverName, "error", err)
 		}
 	}
 
@@ -437,6 +438,6 @@ func (p *DynamicProxyServer) Shutdown() error {
 	p.clients = make(map[string]client.MCPClient)
 	p.serverConfigs = make(map[string]config.ServerConfig)
 
-	log.Printf("Dynamic proxy server shutdown complete")
+	proxyLog.Info("Dynamic proxy server shutdown complete")
 	return nil
 }
\ No newline at end of file
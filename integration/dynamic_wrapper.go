@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"sync"
@@ -16,6 +15,10 @@ import (
 	"mcp-debug/client"
 	"mcp-debug/config"
 	"mcp-debug/discovery"
+	"mcp-debug/logging"
+	"mcp-debug/metrics"
+	"mcp-debug/proxy"
+	"mcp-debug/redact"
 )
 
 // DynamicWrapper provides dynamic server management for mark3labs/mcp-go
@@ -35,9 +38,11 @@ type DynamicServerInfo struct {
 	Name         string
 	Client       client.MCPClient
 	Tools        []string
+	HiddenTools  []string // original names of tools excluded by the filter
 	Config       config.ServerConfig
 	IsConnected  bool
 	ErrorMessage string
+	Withdrawn    bool // tools were deleted from the MCP server after a crash
 }
 
 // RecordedMessage represents a JSON-RPC message with metadata
@@ -47,6 +52,7 @@ type RecordedMessage struct {
 	MessageType string          `json:"message_type"` // "tool_call", "initialize", etc.
 	ToolName    string          `json:"tool_name,omitempty"`
 	ServerName  string          `json:"server_name,omitempty"`
+	Cached      bool            `json:"cached,omitempty"` // response served from the proxy's cache
 	Message     json.RawMessage `json:"message"`
 }
 
@@ -64,6 +70,9 @@ func NewDynamicWrapper(cfg *config.ProxyConfig) *DynamicWrapper {
 		"Dynamic MCP Proxy",
 		"1.0.0",
 		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
+		server.WithLogging(),
 	)
 	
 	// Create proxy server
@@ -76,12 +85,22 @@ func NewDynamicWrapper(cfg *config.ProxyConfig) *DynamicWrapper {
 		dynamicServers: make(map[string]*DynamicServerInfo),
 	}
 	
+	proxyServer.recorder = wrapper.recordMessage
+	proxyServer.metrics.SetServerStates(wrapper.serverStates)
+	proxyServer.dashboard.SetBackend(&dashboardBackend{w: wrapper})
+	
 	// Register management tools
 	wrapper.registerManagementTools()
 	
 	return wrapper
 }
 
+// EnableTracing starts exporting spans of tool calls as configured. Must be
+// called before Initialize so static servers' handlers are traced too.
+func (w *DynamicWrapper) EnableTracing() error {
+	return w.proxyServer.EnableTracing()
+}
+
 // EnableRecording starts recording JSON-RPC traffic to the specified file
 func (w *DynamicWrapper) EnableRecording(filename string) error {
 	w.recordMu.Lock()
@@ -110,12 +129,17 @@ func (w *DynamicWrapper) EnableRecording(filename string) error {
 	fmt.Fprintf(file, "# MCP Recording Session\n# Started: %s\n%s\n", 
 		session.StartTime.Format(time.RFC3339), string(headerBytes))
 	
-	log.Printf("Recording enabled to: %s", filename)
+	transportLog.Info("Recording enabled", "path", filename)
 	return nil
 }
 
 // recordMessage records a JSON-RPC message with metadata
 func (w *DynamicWrapper) recordMessage(direction, messageType, toolName, serverName string, message interface{}) {
+	w.writeRecord(direction, messageType, toolName, serverName, message, false)
+}
+
+// writeRecord appends a message to the recording file
+func (w *DynamicWrapper) writeRecord(direction, messageType, toolName, serverName string, message interface{}, cached bool) {
 	if !w.recordEnabled {
 		return
 	}
@@ -125,7 +149,7 @@ func (w *DynamicWrapper) recordMessage(direction, messageType, toolName, serverN
 	
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
-		log.Printf("Failed to marshal message for recording: %v", err)
+		transportLog.Error("Failed to marshal message for recording", "error", err)
 		return
 	}
 	
@@ -135,12 +159,13 @@ func (w *DynamicWrapper) recordMessage(direction, messageType, toolName, serverN
 		MessageType: messageType,
 		ToolName:    toolName,
 		ServerName:  serverName,
-		Message:     json.RawMessage(messageBytes),
+		Cached:      cached,
+		Message:     json.RawMessage(redact.Default.JSON(messageBytes)),
 	}
 	
 	recordedBytes, err := json.Marshal(recorded)
 	if err != nil {
-		log.Printf("Failed to marshal recorded message: %v", err)
+		transportLog.Error("Failed to marshal recorded message", "error", err)
 		return
 	}
 	
@@ -160,6 +185,14 @@ func (w *DynamicWrapper) registerManagementTools() {
 			mcp.Required(),
 			mcp.Description("Command to run (e.g., 'npx -y @modelcontextprotocol/filesystem /path')"),
 		),
+		mcp.WithArray("include",
+			mcp.Description("Only register tools matching these glob patterns (e.g., 'read_*')"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Never register tools matching these glob patterns"),
+			mcp.WithStringItems(),
+		),
 	)
 	
 	w.baseServer.AddTool(addTool, w.handleServerAdd)
@@ -207,6 +240,78 @@ func (w *DynamicWrapper) registerManagementTools() {
 	)
 	
 	w.baseServer.AddTool(reconnectTool, w.handleServerReconnect)
+	
+	// cache_clear tool
+	cacheClearTool := mcp.NewTool("cache_clear",
+		mcp.WithDescription("Clear cached tool responses"),
+		mcp.WithString("server",
+			mcp.Description("Only clear responses from this server (default: all servers)"),
+		),
+	)
+	
+	w.baseServer.AddTool(cacheClearTool, w.handleCacheClear)
+	
+	// chaos_set tool
+	chaosSetTool := mcp.NewTool("chaos_set",
+		mcp.WithDescription("Inject latency and faults into a server's tool calls. Call without latency or faults to stop."),
+		mcp.WithString("server",
+			mcp.Required(),
+			mcp.Description("Name of the server"),
+		),
+		mcp.WithString("tool",
+			mcp.Description("Original name of the tool (default: '*' for all tools without their own rules)"),
+		),
+		mcp.WithString("latency",
+			mcp.Description("Latency added to each call (e.g., '500ms')"),
+		),
+		mcp.WithString("jitter",
+			mcp.Description("Random extra latency, up to this much (e.g., '1s')"),
+		),
+		mcp.WithNumber("toolError",
+			mcp.Description("Probability (0-1) of a result with isError set"),
+		),
+		mcp.WithNumber("rpcError",
+			mcp.Description("Probability (0-1) of a JSON-RPC internal error"),
+		),
+		mcp.WithNumber("disconnect",
+			mcp.Description("Probability (0-1) of failing as if the connection was lost"),
+		),
+		mcp.WithNumber("drop",
+			mcp.Description("Probability (0-1) of discarding the response so the call times out"),
+		),
+		mcp.WithString("dropTimeout",
+			mcp.Description("How long a call with a dropped response waits (e.g., '5s', default: never times out)"),
+		),
+		mcp.WithNumber("truncate",
+			mcp.Description("Probability (0-1) of cutting the result text short"),
+		),
+		mcp.WithNumber("truncateLength",
+			mcp.Description("Characters kept of truncated results (default: half the text)"),
+		),
+	)
+	
+	w.baseServer.AddTool(chaosSetTool, w.handleChaosSet)
+	
+	// proxy_log_level tool
+	logLevelTool := mcp.NewTool("proxy_log_level",
+		mcp.WithDescription("Show or change the levels of the proxy's own log. Without a level, shows the current levels."),
+		mcp.WithString("level",
+			mcp.Description("debug, info, warn or error; 'reset' makes a component or server use the default level again"),
+		),
+		mcp.WithString("component",
+			mcp.Description("Only set the level of this component: "+strings.Join(logging.Components, ", ")),
+		),
+		mcp.WithString("server",
+			mcp.Description("Only set the level of entries about this server, taking precedence over component levels"),
+		),
+	)
+	
+	w.baseServer.AddTool(logLevelTool, w.handleLogLevel)
+	
+	// Keep child server tools from shadowing the management tools
+	for _, tool := range []mcp.Tool{addTool, removeTool, listTool, disconnectTool, reconnectTool, cacheClearTool, chaosSetTool, logLevelTool} {
+		w.proxyServer.registry.ReserveName(tool.Name)
+	}
 }
 
 func (w *DynamicWrapper) handleServerAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -234,6 +339,11 @@ func (w *DynamicWrapper) handleServerAdd(ctx context.Context, request mcp.CallTo
 	if _, exists := w.dynamicServers[name]; exists {
 		return mcp.NewToolResultError(fmt.Sprintf("Server '%s' already exists", name)), nil
 	}
+	for _, staticServer := range w.proxyServer.config.Servers {
+		if staticServer.Name == name {
+			return mcp.NewToolResultError(fmt.Sprintf("Server '%s' already exists", name)), nil
+		}
+	}
 	
 	// Parse command
 	parts := strings.Fields(command)
@@ -251,8 +361,20 @@ func (w *DynamicWrapper) handleServerAdd(ctx context.Context, request mcp.CallTo
 		Timeout:   "30s",
 	}
 	
+	// Optional tool filter
+	include := request.GetStringSlice("include", nil)
+	exclude := request.GetStringSlice("exclude", nil)
+	if len(include) > 0 || len(exclude) > 0 {
+		serverConfig.Tools = &config.ToolFilter{Include: include, Exclude: exclude}
+		if err := serverConfig.Tools.Validate(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	
 	// Create and connect client
 	stdioClient := client.NewStdioClient(name, serverConfig.Command, serverConfig.Args)
+	stdioClient.SetStderr(logging.ServerStderr(name))
+	stdioClient.SetNotificationHandler(w.proxyServer.childNotificationHandler(name, name, stdioClient))
 	if err := stdioClient.Connect(ctx); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to connect: %v", err)), nil
 	}
@@ -269,52 +391,43 @@ func (w *DynamicWrapper) handleServerAdd(ctx context.Context, request mcp.CallTo
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list tools: %v", err)), nil
 	}
 	
+	// Refuse servers whose tools would shadow existing ones
+	remoteTools, hiddenTools := w.toRemoteTools(&serverConfig, tools)
+	if err := w.proxyServer.registry.CheckConflicts(name, remoteTools); err != nil {
+		stdioClient.Close()
+		return mcp.NewToolResultError(fmt.Sprintf("Tool name collision: %v", err)), nil
+	}
+	
 	// Store server info
 	serverInfo := &DynamicServerInfo{
 		Name:        name,
 		Client:      stdioClient,
 		Config:      serverConfig,
 		Tools:       make([]string, 0, len(tools)),
+		HiddenTools: hiddenTools,
 		IsConnected: true,
 	}
 	
-	// Register tools with proxy
-	registeredCount := 0
-	for _, tool := range tools {
-		// Create discovered tool
-		discoveredTool := discovery.RemoteTool{
-			OriginalName: tool.Name,
-			PrefixedName: fmt.Sprintf("%s_%s", name, tool.Name),
-			Description:  tool.Description,
-			InputSchema:  tool.InputSchema,
-			ServerName:   name,
-		}
-		
-		// Register with proxy registry
-		w.proxyServer.registry.RegisterTool(discoveredTool, stdioClient)
-		
-		// Create MCP tool
-		mcpTool := w.proxyServer.createMCPTool(discoveredTool)
-		
-		// Create proxy handler with disconnect checking
-		handler := w.createDynamicProxyHandler(name, discoveredTool.OriginalName)
-		
-		// Add to MCP server
-		w.baseServer.AddTool(mcpTool, handler)
-		
-		serverInfo.Tools = append(serverInfo.Tools, discoveredTool.PrefixedName)
-		registeredCount++
-		log.Printf("Dynamically registered tool: %s", discoveredTool.PrefixedName)
-	}
+	// Register tools with proxy, behind a circuit breaker with default settings
+	w.proxyServer.registry.SetBreaker(name, proxy.NewCircuitBreaker(serverConfig.CircuitBreaker))
+	w.proxyServer.registry.SetChaos(name, proxy.NewChaos(nil))
+	serverInfo.Tools = w.registerServerTools(name, stdioClient, remoteTools)
+	registeredCount := len(serverInfo.Tools)
 	
+	// Aggregate resources and prompts exposed by the server
+	w.proxyServer.syncServerFeatures(ctx, name, name, stdioClient)
+
 	// Store server info
 	w.dynamicServers[name] = serverInfo
 	
 	// Also add to proxy server's client list
-	w.proxyServer.clients = append(w.proxyServer.clients, stdioClient)
+	w.proxyServer.addClient(stdioClient)
 	
 	result := fmt.Sprintf("Added server '%s' with command: %s %s\nRegistered %d tools successfully.",
 		name, serverConfig.Command, strings.Join(serverConfig.Args, " "), registeredCount)
+	if len(hiddenTools) > 0 {
+		result += fmt.Sprintf("\nHidden %d filtered tools: %s", len(hiddenTools), strings.Join(hiddenTools, ", "))
+	}
 	
 	toolResult := mcp.NewToolResultText(result)
 	w.recordMessage("response", "tool_call", "server_add", "proxy", toolResult)
@@ -335,30 +448,123 @@ func (w *DynamicWrapper) handleServerRemove(ctx context.Context, request mcp.Cal
 		return mcp.NewToolResultError(fmt.Sprintf("Server '%s' not found", name)), nil
 	}
 	
-	// Note: We can't actually remove tools from mark3labs/mcp-go at runtime
-	// But we can close the connection and mark them as unavailable
-	
-	// Close client
-	if err := serverInfo.Client.Close(); err != nil {
-		log.Printf("Error closing client %s: %v", name, err)
+	// Close client (may already be nil after server_disconnect)
+	if serverInfo.Client != nil {
+		if err := serverInfo.Client.Close(); err != nil {
+			serverLog.Warn("Error closing client", "server", name, "error", err)
+		}
 	}
-	
+
+	// Remove tools from the MCP server, which notifies clients of the change
+	removedCount := w.unregisterServerTools(name, serverInfo.Tools)
+	w.proxyServer.registry.UnregisterServer(name)
+	w.proxyServer.removeServerFeatures(name)
+
 	// Remove from maps
 	delete(w.dynamicServers, name)
 	
 	// Remove from proxy server's client list
-	newClients := make([]client.MCPClient, 0, len(w.proxyServer.clients)-1)
-	for _, c := range w.proxyServer.clients {
-		if c != serverInfo.Client {
-			newClients = append(newClients, c)
+	w.proxyServer.removeClient(name)
+	
+	result := fmt.Sprintf("Removed server '%s' and unregistered %d tools.", name, removedCount)
+	
+	return mcp.NewToolResultText(result), nil
+}
+
+func (w *DynamicWrapper) handleCacheClear(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	w.recordMessage("request", "tool_call", "cache_clear", "proxy", request)
+	
+	serverName := request.GetString("server", "")
+	cache := w.proxyServer.registry.GetCache()
+	removed := cache.Clear(serverName)
+	_, hits, misses := cache.Stats()
+	
+	scope := "all servers"
+	if serverName != "" {
+		scope = fmt.Sprintf("server '%s'", serverName)
+	}
+	result := mcp.NewToolResultText(fmt.Sprintf("Cleared %d cached responses from %s (hits: %d, misses: %d)",
+		removed, scope, hits, misses))
+	
+	w.recordMessage("response", "tool_call", "cache_clear", "proxy", result)
+	return result, nil
+}
+
+func (w *DynamicWrapper) handleChaosSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	w.recordMessage("request", "tool_call", "chaos_set", "proxy", request)
+	
+	serverName, err := request.RequireString("server")
+	if err != nil {
+		return mcp.NewToolResultError("server is required"), nil
+	}
+	toolName := request.GetString("tool", "*")
+	
+	w.mu.RLock()
+	chaos := w.proxyServer.registry.GetChaos(serverName)
+	w.mu.RUnlock()
+	if chaos == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Server '%s' not found", serverName)), nil
+	}
+	
+	rules := config.ChaosRules{
+		Latency:        request.GetString("latency", ""),
+		Jitter:         request.GetString("jitter", ""),
+		ToolError:      request.GetFloat("toolError", 0),
+		RPCError:       request.GetFloat("rpcError", 0),
+		Disconnect:     request.GetFloat("disconnect", 0),
+		Drop:           request.GetFloat("drop", 0),
+		DropTimeout:    request.GetString("dropTimeout", ""),
+		Truncate:       request.GetFloat("truncate", 0),
+		TruncateLength: request.GetInt("truncateLength", 0),
+	}
+	if err := rules.Validate(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid chaos rules: %v", err)), nil
+	}
+	chaos.Set(toolName, rules)
+	
+	status := chaos.Status()
+	if status == "" {
+		status = "none"
+	}
+	result := mcp.NewToolResultText(fmt.Sprintf("Chaos for server '%s': %s", serverName, status))
+	
+	w.recordMessage("response", "tool_call", "chaos_set", "proxy", result)
+	return result, nil
+}
+
+func (w *DynamicWrapper) handleLogLevel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	w.recordMessage("request", "tool_call", "proxy_log_level", "proxy", request)
+	
+	level := request.GetString("level", "")
+	component := request.GetString("component", "")
+	serverName := request.GetString("server", "")
+	
+	if level != "" {
+		if level == "reset" {
+			if component == "" && serverName == "" {
+				return mcp.NewToolResultError("reset needs a component or server"), nil
+			}
+			level = ""
+		} else if _, exists := w.serverStates()[serverName]; serverName != "" && !exists {
+			return mcp.NewToolResultError(fmt.Sprintf("Server '%s' not found", serverName)), nil
+		}
+		
+		if err := logging.SetLevel(component, serverName, level); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
+		scope := "default"
+		if component != "" {
+			scope = "component " + component
+		} else if serverName != "" {
+			scope = "server " + serverName
+		}
+		proxyLog.Info("Log level changed", "scope", scope, "level", request.GetString("level", ""))
 	}
-	w.proxyServer.clients = newClients
 	
-	result := fmt.Sprintf("Removed server '%s'. Note: %d tools remain registered but are now unavailable.",
-		name, len(serverInfo.Tools))
+	result := mcp.NewToolResultText(fmt.Sprintf("Proxy log levels:\n%s", logging.Levels()))
 	
-	return mcp.NewToolResultText(result), nil
+	w.recordMessage("response", "tool_call", "proxy_log_level", "proxy", result)
+	return result, nil
 }
 
 func (w *DynamicWrapper) handleServerList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -375,6 +581,9 @@ func (w *DynamicWrapper) handleServerList(ctx context.Context, request mcp.CallT
 		result.WriteString("Static servers (from config):\n")
 		for _, server := range w.proxyServer.config.Servers {
 			result.WriteString(fmt.Sprintf("- %s [static]\n", server.Name))
+			writeCircuitState(&result, w.proxyServer.registry.GetBreaker(server.Name))
+			writeChaos(&result, w.proxyServer.registry.GetChaos(server.Name))
+			writeHiddenTools(&result, w.proxyServer.hiddenTools[server.Name])
 		}
 		result.WriteString("\n")
 	}
@@ -405,6 +614,9 @@ func (w *DynamicWrapper) handleServerList(ctx context.Context, request mcp.CallT
 				}
 				result.WriteString(fmt.Sprintf("  • ... and %d more\n", len(info.Tools)-3))
 			}
+			writeCircuitState(&result, w.proxyServer.registry.GetBreaker(name))
+			writeChaos(&result, w.proxyServer.registry.GetChaos(name))
+			writeHiddenTools(&result, info.HiddenTools)
 		}
 	}
 	
@@ -412,7 +624,44 @@ func (w *DynamicWrapper) handleServerList(ctx context.Context, request mcp.CallT
 	result.WriteString(fmt.Sprintf("\nTotal servers: %d (static: %d, dynamic: %d)\n",
 		totalServers, staticCount, len(w.dynamicServers)))
 	
-	return mcp.NewToolResultText(result.String()), nil
+	return mcp.NewToolResultText(redact.Default.String(result.String())), nil
+}
+
+// writeCircuitState shows a server's circuit breaker state
+func writeCircuitState(result *strings.Builder, breaker *proxy.CircuitBreaker) {
+	result.WriteString(fmt.Sprintf("  circuit: %s\n", breaker.Status()))
+}
+
+// serverStates reports whether each static and dynamic server is connected
+func (w *DynamicWrapper) serverStates() map[string]bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	
+	states := make(map[string]bool)
+	for _, serverConfig := range w.proxyServer.config.Servers {
+		states[serverConfig.Name] = false
+	}
+	for _, c := range w.proxyServer.clientList() {
+		states[c.ServerName()] = c.IsConnected()
+	}
+	for name, info := range w.dynamicServers {
+		states[name] = info.IsConnected
+	}
+	return states
+}
+
+// writeChaos shows the faults injected into a server's calls, if any
+func writeChaos(result *strings.Builder, chaos *proxy.Chaos) {
+	if status := chaos.Status(); status != "" {
+		result.WriteString(fmt.Sprintf("  chaos: %s\n", status))
+	}
+}
+
+// writeHiddenTools lists the tools a server's filter keeps from being registered
+func writeHiddenTools(result *strings.Builder, hiddenTools []string) {
+	if len(hiddenTools) > 0 {
+		result.WriteString(fmt.Sprintf("  hidden: %s\n", strings.Join(hiddenTools, ", ")))
+	}
 }
 
 func (w *DynamicWrapper) handleServerDisconnect(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -433,13 +682,13 @@ func (w *DynamicWrapper) handleServerDisconnect(ctx context.Context, request mcp
 		return mcp.NewToolResultText(fmt.Sprintf("Server '%s' is already disconnected", name)), nil
 	}
 	
-	log.Printf("Disconnecting server '%s'", name)
+	serverLog.Info("Disconnecting server", "server", name)
 	
 	// Close client and terminate process
 	if serverInfo.Client != nil {
-		log.Printf("Terminating process for server '%s'", name)
+		serverLog.Debug("Terminating server process", "server", name)
 		if err := serverInfo.Client.Close(); err != nil {
-			log.Printf("Error closing client %s: %v", name, err)
+			serverLog.Warn("Error closing client", "server", name, "error", err)
 		}
 	}
 	
@@ -475,7 +724,7 @@ func (w *DynamicWrapper) handleServerReconnect(ctx context.Context, request mcp.
 		return mcp.NewToolResultError(fmt.Sprintf("Server '%s' is still connected. Use server_disconnect first.", name)), nil
 	}
 	
-	log.Printf("Reconnecting server '%s' with new command: %s", name, command)
+	serverLog.Info("Reconnecting server", "server", name, "command", command)
 	
 	// Parse new command
 	parts := strings.Fields(command)
@@ -483,7 +732,7 @@ func (w *DynamicWrapper) handleServerReconnect(ctx context.Context, request mcp.
 		return mcp.NewToolResultError("Invalid command"), nil
 	}
 	
-	// Update server config
+	// Update server config, keeping the tool filter
 	serverConfig := config.ServerConfig{
 		Name:      name,
 		Prefix:    name,
@@ -491,10 +740,13 @@ func (w *DynamicWrapper) handleServerReconnect(ctx context.Context, request mcp.
 		Command:   parts[0],
 		Args:      parts[1:],
 		Timeout:   "30s",
+		Tools:     serverInfo.Config.Tools,
 	}
 	
 	// Create and connect new client
 	stdioClient := client.NewStdioClient(name, serverConfig.Command, serverConfig.Args)
+	stdioClient.SetStderr(logging.ServerStderr(name))
+	stdioClient.SetNotificationHandler(w.proxyServer.childNotificationHandler(name, name, stdioClient))
 	if err := stdioClient.Connect(ctx); err != nil {
 		// Mark as disconnected but keep tools registered
 		serverInfo.IsConnected = false
@@ -521,136 +773,267 @@ func (w *DynamicWrapper) handleServerReconnect(ctx context.Context, request mcp.
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list tools: %v", err)), nil
 	}
 	
-	// Update server info
+	// Keep the old tools registered if the new binary's tools would collide
+	newTools, hiddenTools := w.toRemoteTools(&serverConfig, tools)
+	if err := w.proxyServer.registry.CheckConflicts(name, newTools); err != nil {
+		stdioClient.Close()
+		serverInfo.IsConnected = false
+		serverInfo.ErrorMessage = fmt.Sprintf("Tool name collision: %v", err)
+		serverInfo.Config = serverConfig
+		return mcp.NewToolResultError(fmt.Sprintf("Tool name collision: %v", err)), nil
+	}
+	
+	// Update server info, stopping the previous process if it is still around
+	if serverInfo.Client != nil {
+		if err := serverInfo.Client.Close(); err != nil {
+			serverLog.Warn("Error closing previous client", "server", name, "error", err)
+		}
+	}
 	serverInfo.Client = stdioClient
 	serverInfo.Config = serverConfig
 	serverInfo.IsConnected = true
 	serverInfo.ErrorMessage = ""
 	
+	w.proxyServer.metrics.ServerRestarted(name)
+	
+	// The new binary starts with a closed circuit and no cached results
+	w.proxyServer.registry.SetBreaker(name, proxy.NewCircuitBreaker(serverConfig.CircuitBreaker))
+	w.proxyServer.registry.GetCache().Clear(name)
+	
 	// Update proxy server's client list
-	for i, c := range w.proxyServer.clients {
-		if c.ServerName() == name {
-			w.proxyServer.clients[i] = stdioClient
-			break
+	w.proxyServer.replaceClient(name, stdioClient)
+	
+	// Compare the new tool set against what was registered before
+	var oldTools []discovery.RemoteTool
+	for _, toolName := range serverInfo.Tools {
+		if tool, exists := w.proxyServer.registry.GetTool(toolName); exists {
+			oldTools = append(oldTools, tool)
 		}
 	}
+	diff := discovery.DiffTools(oldTools, newTools)
+	
+	// Drop removed tools and register only added and changed ones, so clients
+	// are notified only if the tool list changed. Unchanged tools keep their
+	// handlers, which look up the new client per call, unless a crash
+	// withdrew them from the MCP server.
+	removedNames := make([]string, 0, len(diff.Removed))
+	for _, tool := range diff.Removed {
+		removedNames = append(removedNames, tool.PrefixedName)
+	}
+	w.unregisterServerTools(name, removedNames)
 	
-	// Update registry with new client (tools keep same names)
-	for _, tool := range tools {
-		prefixedName := fmt.Sprintf("%s_%s", name, tool.Name)
-		
-		// Check if this tool name exists in our registered tools
-		found := false
-		for _, registeredTool := range serverInfo.Tools {
-			if registeredTool == prefixedName {
-				found = true
-				break
-			}
+	changedTools := append([]discovery.RemoteTool(nil), diff.Added...)
+	for _, change := range diff.Changed {
+		changedTools = append(changedTools, change.Tool)
+	}
+	if serverInfo.Withdrawn {
+		changedTools = append(changedTools, diff.Unchanged...)
+	} else {
+		for _, tool := range diff.Unchanged {
+			w.proxyServer.registry.RegisterTool(tool, stdioClient)
 		}
+	}
+	w.registerServerTools(name, stdioClient, changedTools)
+	serverInfo.Withdrawn = false
+	
+	serverInfo.Tools = make([]string, 0, len(newTools))
+	for _, tool := range newTools {
+		serverInfo.Tools = append(serverInfo.Tools, tool.PrefixedName)
+	}
+	serverInfo.HiddenTools = hiddenTools
+	
+	// Re-discover resources and prompts, restoring subscriptions on the new connection
+	w.proxyServer.syncServerFeatures(ctx, name, name, stdioClient)
+	
+	result := fmt.Sprintf("Reconnected server '%s' with command: %s %s\n%s",
+		name, serverConfig.Command, strings.Join(serverConfig.Args, " "), discovery.FormatToolDiff(diff))
+	
+	return mcp.NewToolResultText(result), nil
+}
+
+// registerServerTools registers a server's tools with the proxy registry and
+// adds them to the MCP server in a single batch, so connected clients receive
+// one notifications/tools/list_changed. Returns the prefixed tool names.
+func (w *DynamicWrapper) registerServerTools(serverName string, mcpClient client.MCPClient, tools []discovery.RemoteTool) []string {
+	names := make([]string, 0, len(tools))
+	serverTools := make([]server.ServerTool, 0, len(tools))
+	
+	for _, discoveredTool := range tools {
+		// Register with proxy registry
+		w.proxyServer.registry.RegisterTool(discoveredTool, mcpClient)
 		
-		if found {
-			// Update registry with new client
-			discoveredTool := discovery.RemoteTool{
-				OriginalName: tool.Name,
-				PrefixedName: prefixedName,
-				Description:  tool.Description,
-				InputSchema:  tool.InputSchema,
-				ServerName:   name,
-			}
-			w.proxyServer.registry.RegisterTool(discoveredTool, stdioClient)
-			log.Printf("Updated tool registration: %s", prefixedName)
+		// Create MCP tool with a handler that checks connection status
+		handler := w.proxyServer.instrument(serverName, discoveredTool.PrefixedName,
+			w.createDynamicProxyHandler(serverName, discoveredTool.PrefixedName))
+		w.proxyServer.registry.SetHandler(discoveredTool.PrefixedName, handler)
+		serverTools = append(serverTools, server.ServerTool{
+			Tool:    w.proxyServer.createMCPTool(discoveredTool),
+			Handler: handler,
+		})
+		
+		names = append(names, discoveredTool.PrefixedName)
+		serverLog.Debug("Dynamically registered tool", "server", serverName, "tool", discoveredTool.PrefixedName)
+	}
+	
+	if len(serverTools) > 0 {
+		w.baseServer.AddTools(serverTools...)
+	}
+	
+	return names
+}
+
+// toRemoteTools converts tools listed by a dynamic server into remote tools
+// named by the naming policy. Dynamic servers use their name as prefix.
+// Returns the tools passing the filter and the original names of the rest.
+func (w *DynamicWrapper) toRemoteTools(serverConfig *config.ServerConfig, tools []client.ToolInfo) ([]discovery.RemoteTool, []string) {
+	remoteTools := make([]discovery.RemoteTool, 0, len(tools))
+	var hidden []string
+	for _, tool := range tools {
+		if !serverConfig.Tools.Allows(tool.Name) {
+			hidden = append(hidden, tool.Name)
+			continue
 		}
+		remoteTool := discovery.CreatePrefixedTool(w.proxyServer.naming, serverConfig.Name, serverConfig.Name, discovery.ToolInfo{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema:  tool.InputSchema,
+			OutputSchema: tool.OutputSchema,
+			Annotations:  tool.Annotations,
+		})
+		remoteTool.CacheTTL = w.proxyServer.config.CacheTTLFor(serverConfig, tool.Name, tool.Annotations.IsReadOnly())
+		remoteTool.InputValidation = w.proxyServer.config.InputValidationFor(serverConfig)
+		remoteTool.OutputValidation = w.proxyServer.config.OutputValidationFor(serverConfig)
+		remoteTools = append(remoteTools, remoteTool)
+	}
+	return remoteTools, hidden
+}
+
+// unregisterServerTools deletes tools from the MCP server and the proxy
+// registry. mcp-go sends notifications/tools/list_changed when anything was
+// actually removed. Returns the number of tools removed.
+func (w *DynamicWrapper) unregisterServerTools(serverName string, toolNames []string) int {
+	if len(toolNames) == 0 {
+		return 0
 	}
 	
-	result := fmt.Sprintf("Reconnected server '%s' with command: %s %s\\nServer now connected and tools updated.",
-		name, serverConfig.Command, strings.Join(serverConfig.Args, " "))
+	w.baseServer.DeleteTools(toolNames...)
+	for _, toolName := range toolNames {
+		w.proxyServer.registry.UnregisterTool(toolName)
+		serverLog.Debug("Unregistered tool", "server", serverName, "tool", toolName)
+	}
 	
-	return mcp.NewToolResultText(result), nil
+	serverLog.Info("Removed tools", "server", serverName, "tools", len(toolNames))
+	return len(toolNames)
+}
+
+// handleServerCrash marks a server as disconnected after a connection failure
+// and withdraws its tools so clients only see tools that can be called.
+// The tool names are kept in serverInfo so server_reconnect can restore them.
+// Errors from a client that has since been replaced are ignored.
+// Callers must hold w.mu.
+func (w *DynamicWrapper) handleServerCrash(serverInfo *DynamicServerInfo, failedClient client.MCPClient, err error) {
+	if !serverInfo.IsConnected || serverInfo.Client != failedClient {
+		return
+	}
+	
+	serverLog.Error("Server connection lost", "server", serverInfo.Name, "error", err)
+	
+	// A timed-out server may still be running; stop it and its reader
+	if serverInfo.Client != nil {
+		if closeErr := serverInfo.Client.Close(); closeErr != nil {
+			serverLog.Warn("Error closing client", "server", serverInfo.Name, "error", closeErr)
+		}
+		serverInfo.Client = nil
+	}
+	
+	serverInfo.IsConnected = false
+	serverInfo.ErrorMessage = err.Error()
+	
+	if len(serverInfo.Tools) > 0 {
+		w.baseServer.DeleteTools(serverInfo.Tools...)
+		serverInfo.Withdrawn = true
+	}
+	w.proxyServer.removeServerFeatures(serverInfo.Name)
 }
 
-// createDynamicProxyHandler creates a handler that checks connection status
-func (w *DynamicWrapper) createDynamicProxyHandler(serverName, originalToolName string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// createDynamicProxyHandler creates a handler that checks the server is
+// connected and forwards the call through the shared proxy pipeline. A lost
+// connection marks the server as crashed.
+func (w *DynamicWrapper) createDynamicProxyHandler(serverName, prefixedToolName string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Steps of a virtual tool are recorded with the virtual tool call
+		recording := !isVirtualStep(ctx)
+		record := func(direction string, message interface{}, cached bool) {
+			if recording {
+				w.writeRecord(direction, "tool_call", prefixedToolName, serverName, message, cached)
+			}
+		}
+		
 		// Record the tool call request
-		prefixedToolName := fmt.Sprintf("%s_%s", serverName, originalToolName)
-		w.recordMessage("request", "tool_call", prefixedToolName, serverName, request)
+		record("request", request, false)
+		
 		w.mu.RLock()
 		serverInfo, exists := w.dynamicServers[serverName]
+		var mcpClient client.MCPClient
+		var connected bool
+		var errorMessage string
+		if exists {
+			mcpClient, connected, errorMessage = serverInfo.Client, serverInfo.IsConnected, serverInfo.ErrorMessage
+		}
+		remoteTool, registered := w.proxyServer.registry.GetTool(prefixedToolName)
 		w.mu.RUnlock()
 		
-		if !exists {
+		if !exists || !registered {
+			metrics.SetErrorKind(ctx, metrics.ErrorConnection)
 			result := mcp.NewToolResultError(fmt.Sprintf("Server '%s' not found", serverName))
-			w.recordMessage("response", "tool_call", prefixedToolName, serverName, result)
+			if exists {
+				result = mcp.NewToolResultError(fmt.Sprintf("Tool '%s' not found", prefixedToolName))
+			}
+			record("response", result, false)
 			return result, nil
 		}
 		
-		if !serverInfo.IsConnected {
+		if !connected || mcpClient == nil {
+			metrics.SetErrorKind(ctx, metrics.ErrorConnection)
 			errorMsg := fmt.Sprintf("Server '%s' is disconnected", serverName)
-			if serverInfo.ErrorMessage != "" {
-				errorMsg += fmt.Sprintf(": %s", serverInfo.ErrorMessage)
+			if errorMessage != "" {
+				errorMsg += fmt.Sprintf(": %s", errorMessage)
 			}
 			errorMsg += "\nUse server_reconnect to restore connection."
 			result := mcp.NewToolResultError(errorMsg)
-			w.recordMessage("response", "tool_call", prefixedToolName, serverName, result)
+			record("response", result, false)
 			return result, nil
 		}
 		
-		// Extract arguments from the request
-		args := request.GetArguments()
-		argsMap := make(map[string]interface{})
-		for key, value := range args {
-			argsMap[key] = value
-		}
+		// The handler is built per call, so it uses the server's current
+		// client, circuit breaker and fault injection
+		var callErr error
+		cached := false
+		opts := w.proxyServer.handlerOptions(serverName)
+		opts.OnCallError = func(err error) { callErr = err }
+		opts.OnCacheHit = func() { cached = true }
 		
-		// Forward the call to the remote server
-		result, err := serverInfo.Client.CallTool(ctx, originalToolName, argsMap)
+		result, err := proxy.ForwardHandler(mcpClient, remoteTool, opts)(ctx, request)
 		if err != nil {
-			// Mark server as disconnected on connection errors
-			if isConnectionError(err) {
-				w.mu.Lock()
-				serverInfo.IsConnected = false
-				serverInfo.ErrorMessage = err.Error()
-				w.mu.Unlock()
-				
-				errorMsg := fmt.Sprintf("Server '%s' connection failed: %v\nUse server_reconnect to restore connection.", serverName, err)
-				result := mcp.NewToolResultError(errorMsg)
-				w.recordMessage("response", "tool_call", prefixedToolName, serverName, result)
-				return result, nil
+			if proxy.IsRPCFault(err) {
+				record("response", client.JSONRPCError{Code: errCodeInternalError, Message: err.Error()}, false)
 			}
-			
-			// Wrap error with server context
-			errorMsg := fmt.Sprintf("[%s] %v", serverName, err)
-			result := mcp.NewToolResultError(errorMsg)
-			w.recordMessage("response", "tool_call", prefixedToolName, serverName, result)
-			return result, nil
+			return nil, err
 		}
 		
-		// Transform the result back to MCP format
-		var finalResult *mcp.CallToolResult
-		if result.IsError {
-			if len(result.Content) > 0 {
-				finalResult = mcp.NewToolResultError(result.Content[0].Text)
-			} else {
-				finalResult = mcp.NewToolResultError("Tool execution failed")
-			}
-		} else {
-			// For successful results, convert content to text
-			if len(result.Content) > 0 {
-				var text string
-				for i, content := range result.Content {
-					if i > 0 {
-						text += "\n"
-					}
-					text += content.Text
-				}
-				finalResult = mcp.NewToolResultText(text)
-			} else {
-				finalResult = mcp.NewToolResultText("Tool executed successfully")
+		// Mark server as disconnected on connection errors. Injected
+		// disconnects and timeouts leave the server running.
+		if callErr != nil && isConnectionError(callErr) {
+			if !proxy.IsFault(callErr) {
+				w.mu.Lock()
+				w.handleServerCrash(serverInfo, mcpClient, callErr)
+				w.mu.Unlock()
 			}
+			result = mcp.NewToolResultError(fmt.Sprintf("Server '%s' connection failed: %v\nUse server_reconnect to restore connection.", serverName, callErr))
 		}
 		
-		w.recordMessage("response", "tool_call", prefixedToolName, serverName, finalResult)
-		return finalResult, nil
+		record("response", result, cached)
+		return result, nil
 	}
 }
 
@@ -672,6 +1055,28 @@ func (w *DynamicWrapper) Initialize(ctx context.Context) error {
 
 // Start starts the MCP server
 func (w *DynamicWrapper) Start() error {
-	log.Println("Starting Dynamic MCP Proxy Server with management tools...")
-	return server.ServeStdio(w.baseServer)
+	proxyLog.Info("Starting Dynamic MCP Proxy Server with management tools...")
+	if listen := w.proxyServer.config.Metrics.Listen; listen != "" {
+		if err := w.proxyServer.metrics.Listen(listen); err != nil {
+			return err
+		}
+		proxyLog.Info("Serving metrics", "url", "http://"+listen+"/metrics")
+	}
+	if listen := w.proxyServer.config.Dashboard.Listen; listen != "" {
+		if err := w.proxyServer.dashboard.Listen(listen); err != nil {
+			return err
+		}
+		proxyLog.Info("Serving dashboard", "url", "http://"+listen+"/")
+	}
+	transport := newStdioTransport(w.baseServer)
+	w.proxyServer.registerResourceHandlers(transport)
+	w.proxyServer.registerCompletionHandlers(transport)
+	w.proxyServer.registerLoggingHandlers(transport)
+	err := transport.Serve()
+	
+	// Export the spans of the last calls before exiting
+	if closeErr := w.proxyServer.tracer.Close(); closeErr != nil {
+		proxyLog.Error("Failed to export traces", "error", closeErr)
+	}
+	return err
 }
\ No newline at end of file
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+	"mcp-debug/logging"
+)
+
+// Loggers of the proxy's components
+var (
+	proxyLog     = logging.For(logging.ComponentProxy)
+	serverLog    = logging.For(logging.ComponentServer)
+	transportLog = logging.For(logging.ComponentTransport)
+)
+
+// methodNotificationMessage is the MCP log message notification, which mcp-go
+// has no method constant for
+const methodNotificationMessage = "notifications/message"
+
+// logLevelState remembers the level requested by the upstream client so it
+// can be applied to servers that connect later
+type logLevelState struct {
+	level string
+	mu    sync.RWMutex
+}
+
+func (s *logLevelState) get() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.level
+}
+
+func (s *logLevelState) set(level string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.level = level
+}
+
+// registerLoggingHandlers fans logging/setLevel out to the child servers
+func (p *ProxyServer) registerLoggingHandlers(t *stdioTransport) {
+	t.Handle("logging/setLevel", p.handleSetLevel)
+}
+
+func (p *ProxyServer) handleSetLevel(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var request client.SetLevelParams
+	if err := json.Unmarshal(params, &request); err != nil || !config.IsValidLogLevel(request.Level) {
+		return nil, newRPCError(errCodeInvalidParams, "invalid logging level '%s'", request.Level)
+	}
+	p.logLevel.set(request.Level)
+
+	for _, mcpClient := range p.clientList() {
+		if mcpClient.IsConnected() {
+			p.applyServerLogLevel(ctx, mcpClient.ServerName(), mcpClient)
+		}
+	}
+	return struct{}{}, nil
+}
+
+// applyServerLogLevel sets a server's log level: the server's configured
+// logLevel if present, otherwise the level last requested by the client
+func (p *ProxyServer) applyServerLogLevel(ctx context.Context, serverName string, mcpClient client.MCPClient) {
+	if !client.HasCapability(mcpClient.ServerCapabilities(), "logging") {
+		return
+	}
+
+	level := p.logLevel.get()
+	if configured := p.configuredLogLevel(serverName); configured != "" {
+		level = configured
+	}
+	if level == "" {
+		return
+	}
+
+	if err := mcpClient.SetLogLevel(ctx, level); err != nil {
+		serverLog.Warn("Failed to set log level", "server", serverName, "level", level, "error", err)
+	}
+}
+
+// configuredLogLevel returns the logLevel override from the server's config
+func (p *ProxyServer) configuredLogLevel(serverName string) string {
+	if p.config == nil {
+		return ""
+	}
+	for _, serverConfig := range p.config.Servers {
+		if serverConfig.Name == serverName {
+			return serverConfig.LogLevel
+		}
+	}
+	return ""
+}
+
+// forwardLogMessage relays a child's log notification to the client with the
+// logger field naming the originating server
+func (p *ProxyServer) forwardLogMessage(serverName string, params json.RawMessage) {
+	var message map[string]interface{}
+	if err := json.Unmarshal(params, &message); err != nil {
+		serverLog.Warn("Invalid log notification", "server", serverName, "error", err)
+		return
+	}
+
+	logger := serverName
+	if childLogger, ok := message["logger"].(string); ok && childLogger != "" {
+		logger = serverName + "/" + childLogger
+	}
+	message["logger"] = logger
+
+	if p.mcpServer != nil {
+		p.mcpServer.SendNotificationToAllClients(methodNotificationMessage, message)
+	}
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-debug/client"
+)
+
+// childNotificationHandler relays notifications from a child server to the
+// upstream client, translating resource URIs and prompt names into the proxy
+// namespace
+func (p *ProxyServer) childNotificationHandler(serverName, prefix string, mcpClient client.MCPClient) client.NotificationHandler {
+	return func(method string, params json.RawMessage) {
+		switch method {
+		case mcp.MethodNotificationResourcesListChanged:
+			// Refresh asynchronously: the handler runs on the client's read loop,
+			// which must stay free to deliver the list responses
+			go func() {
+				if err := p.refreshServerResources(context.Background(), serverName, prefix, mcpClient); err != nil {
+					serverLog.Warn("Failed to refresh resources", "server", serverName, "error", err)
+				}
+			}()
+		case mcp.MethodNotificationPromptsListChanged:
+			go func() {
+				if err := p.refreshServerPrompts(context.Background(), serverName, prefix, mcpClient); err != nil {
+					serverLog.Warn("Failed to refresh prompts", "server", serverName, "error", err)
+				}
+			}()
+		case methodNotificationMessage:
+			p.forwardLogMessage(serverName, params)
+		case mcp.MethodNotificationResourceUpdated:
+			var updated struct {
+				URI string `json:"uri"`
+			}
+			if err := json.Unmarshal(params, &updated); err != nil {
+				serverLog.Warn("Invalid resources/updated notification", "server", serverName, "error", err)
+				return
+			}
+			proxyURI, ok := p.resources.ToProxyURI(serverName, updated.URI)
+			if !ok || p.mcpServer == nil {
+				return
+			}
+			p.mcpServer.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{
+				"uri": proxyURI,
+			})
+		}
+	}
+}
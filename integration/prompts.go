@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-debug/client"
+	"mcp-debug/discovery"
+)
+
+// refreshServerPrompts (re-)discovers the prompts of a server and registers
+// them with the MCP server under prefixed names. mcp-go sends
+// notifications/prompts/list_changed to clients when prompts are added or removed.
+func (p *ProxyServer) refreshServerPrompts(ctx context.Context, serverName, prefix string, mcpClient client.MCPClient) error {
+	if !client.HasCapability(mcpClient.ServerCapabilities(), "prompts") {
+		p.removeServerPrompts(serverName)
+		return nil
+	}
+
+	promptInfos, err := mcpClient.ListPrompts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list prompts: %w", err)
+	}
+
+	prompts := make([]discovery.RemotePrompt, 0, len(promptInfos))
+	serverPrompts := make([]server.ServerPrompt, 0, len(promptInfos))
+	for _, info := range promptInfos {
+		arguments := make([]discovery.PromptArgument, 0, len(info.Arguments))
+		for _, arg := range info.Arguments {
+			arguments = append(arguments, discovery.PromptArgument{
+				Name:        arg.Name,
+				Description: arg.Description,
+				Required:    arg.Required,
+			})
+		}
+
+		remotePrompt := discovery.CreatePrefixedPrompt(p.naming, serverName, prefix, discovery.PromptInfo{
+			Name:        info.Name,
+			Description: info.Description,
+			Arguments:   arguments,
+		})
+		prompts = append(prompts, remotePrompt)
+		serverPrompts = append(serverPrompts, server.ServerPrompt{
+			Prompt:  createMCPPrompt(remotePrompt),
+			Handler: p.createPromptHandler(remotePrompt.PrefixedName),
+		})
+	}
+
+	stale, err := p.prompts.SetServerPrompts(serverName, mcpClient, prompts)
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		p.mcpServer.DeletePrompts(stale...)
+	}
+	if len(serverPrompts) > 0 {
+		p.mcpServer.AddPrompts(serverPrompts...)
+	}
+
+	serverLog.Info("Registered prompts", "server", serverName, "prompts", len(prompts))
+	return nil
+}
+
+// removeServerPrompts unregisters all prompts of a server
+func (p *ProxyServer) removeServerPrompts(serverName string) {
+	removed := p.prompts.RemoveServer(serverName)
+	if len(removed) > 0 {
+		p.mcpServer.DeletePrompts(removed...)
+		serverLog.Info("Removed prompts", "server", serverName, "prompts", len(removed))
+	}
+}
+
+// createPromptHandler creates a handler that forwards prompts/get to the
+// server owning the prompt, using the original prompt name
+func (p *ProxyServer) createPromptHandler(prefixedName string) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		prompt, mcpClient, exists := p.prompts.GetPrompt(prefixedName)
+		if !exists {
+			return nil, fmt.Errorf("prompt '%s' is no longer available", prefixedName)
+		}
+
+		result, err := mcpClient.GetPrompt(ctx, prompt.OriginalName, request.Params.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] %v", prompt.ServerName, err)
+		}
+
+		// Round-trip through JSON so mcp-go decodes every content type
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode prompt result: %w", err)
+		}
+		rawMessage := json.RawMessage(raw)
+		return mcp.ParseGetPromptResult(&rawMessage)
+	}
+}
+
+// createMCPPrompt creates an mcp.Prompt from a RemotePrompt, preserving its arguments
+func createMCPPrompt(remotePrompt discovery.RemotePrompt) mcp.Prompt {
+	prompt := mcp.Prompt{
+		Name:        remotePrompt.PrefixedName,
+		Description: fmt.Sprintf("[%s] %s", remotePrompt.ServerName, remotePrompt.Description),
+	}
+	for _, arg := range remotePrompt.Arguments {
+		prompt.Arguments = append(prompt.Arguments, mcp.PromptArgument{
+			Name:        arg.Name,
+			Description: arg.Description,
+			Required:    arg.Required,
+		})
+	}
+	return prompt
+}
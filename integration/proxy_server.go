@@ -3,7 +3,6 @@ package integration
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	
 	"github.com/mark3labs/mcp-go/mcp"
@@ -11,8 +10,13 @@ import (
 	
 	"mcp-debug/client"
 	"mcp-debug/config"
+	"mcp-debug/dashboard"
 	"mcp-debug/discovery"
+	"mcp-debug/logging"
+	"mcp-debug/metrics"
 	"mcp-debug/proxy"
+	"mcp-debug/schema"
+	"mcp-debug/tracing"
 )
 
 // ProxyServer manages the complete MCP proxy server
@@ -20,8 +24,19 @@ type ProxyServer struct {
 	config       *config.ProxyConfig
 	mcpServer    *server.MCPServer
 	registry     *proxy.ToolRegistry
-	clients      []client.MCPClient
+	resources    *proxy.ResourceRegistry
+	prompts      *proxy.PromptRegistry
+	logLevel     logLevelState
+	clients      []client.MCPClient // guarded by mu, also for dynamic servers
 	discoverer   *discovery.Discoverer
+	naming       *discovery.NamingPolicy
+	hiddenTools  map[string][]string  // static server name -> filtered-out tool names
+	metrics      *metrics.Metrics     // nil unless the metrics endpoint is enabled
+	tracer       *tracing.Tracer      // nil unless tracing is enabled
+	dashboard    *dashboard.Dashboard // nil unless the dashboard is enabled
+	
+	// recorder, when set, records tool calls handled by the proxy itself
+	recorder     func(direction, messageType, toolName, serverName string, message interface{})
 	
 	mu           sync.RWMutex
 	initialized  bool
@@ -29,11 +44,30 @@ type ProxyServer struct {
 
 // NewProxyServer creates a new proxy server with the given configuration
 func NewProxyServer(cfg *config.ProxyConfig) *ProxyServer {
+	registry := proxy.NewToolRegistry()
+	registry.SetCache(proxy.NewResponseCache(cfg.Cache))
+	
+	var proxyMetrics *metrics.Metrics
+	if cfg.Metrics.Listen != "" {
+		proxyMetrics = metrics.New()
+	}
+	
+	var proxyDashboard *dashboard.Dashboard
+	if cfg.Dashboard.Listen != "" {
+		proxyDashboard = dashboard.New()
+	}
+	
 	return &ProxyServer{
-		config:     cfg,
-		registry:   proxy.NewToolRegistry(),
-		discoverer: discovery.NewDiscoverer(cfg),
-		clients:    make([]client.MCPClient, 0),
+		config:      cfg,
+		registry:    registry,
+		resources:   proxy.NewResourceRegistry(),
+		prompts:     proxy.NewPromptRegistry(),
+		discoverer:  discovery.NewDiscoverer(cfg),
+		naming:      discovery.NewNamingPolicy(cfg),
+		hiddenTools: make(map[string][]string),
+		metrics:     proxyMetrics,
+		dashboard:   proxyDashboard,
+		clients:     make([]client.MCPClient, 0),
 	}
 }
 
@@ -46,17 +80,22 @@ func (p *ProxyServer) Initialize(ctx context.Context) error {
 		return nil
 	}
 	
-	log.Println("Initializing Dynamic MCP Proxy Server...")
+	proxyLog.Info("Initializing Dynamic MCP Proxy Server...")
 	
-	// Create MCP server instance
-	p.mcpServer = server.NewMCPServer(
-		"Dynamic MCP Proxy",
-		"1.0.0",
-		server.WithToolCapabilities(true),
-	)
+	// Create MCP server instance unless a wrapper already provided one
+	if p.mcpServer == nil {
+		p.mcpServer = server.NewMCPServer(
+			"Dynamic MCP Proxy",
+			"1.0.0",
+			server.WithToolCapabilities(true),
+			server.WithResourceCapabilities(true, true),
+			server.WithPromptCapabilities(true),
+			server.WithLogging(),
+		)
+	}
 	
 	// Discover tools from all configured servers
-	log.Println("Discovering tools from remote servers...")
+	serverLog.Info("Discovering tools from remote servers...")
 	results, err := p.discoverer.DiscoverAll(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to discover tools: %w", err)
@@ -67,27 +106,42 @@ func (p *ProxyServer) Initialize(ctx context.Context) error {
 	failedResults := discovery.GetFailedResults(results)
 	
 	// Log discovery summary
-	log.Printf("Discovery complete: %d successful, %d failed", len(successfulResults), len(failedResults))
+	serverLog.Info("Discovery complete", "successful", len(successfulResults), "failed", len(failedResults))
 	
 	// Report failed discoveries
 	for _, result := range failedResults {
-		log.Printf("Failed to discover tools from %s: %v", result.ServerName, result.Error)
+		serverLog.Error("Failed to discover tools", "server", result.ServerName, "error", result.Error)
 	}
 	
 	// Process successful discoveries
 	totalTools := 0
 	for _, result := range successfulResults {
-		log.Printf("Discovered %d tools from %s in %v", result.ToolCount(), result.ServerName, result.Duration)
+		serverLog.Info("Discovered tools", "server", result.ServerName, "tools", result.ToolCount(), "duration", result.Duration)
 		totalTools += result.ToolCount()
+		if len(result.HiddenTools) > 0 {
+			serverLog.Info("Hiding filtered tools", "server", result.ServerName, "tools", len(result.HiddenTools))
+			p.hiddenTools[result.ServerName] = result.HiddenTools
+		}
+		
+		// Ambiguous names would route calls to the wrong server, so refuse to start
+		if err := p.registry.CheckConflicts(result.ServerName, result.Tools); err != nil {
+			return fmt.Errorf("tool name collision: %w", err)
+		}
 		
 		// Connect to the server and keep client alive
 		mcpClient, err := p.createAndConnectClient(ctx, result.ServerName)
 		if err != nil {
-			log.Printf("Warning: Failed to create persistent client for %s: %v", result.ServerName, err)
+			serverLog.Warn("Failed to create persistent client", "server", result.ServerName, "error", err)
 			continue
 		}
 		
 		p.clients = append(p.clients, mcpClient)
+		p.registry.SetLimiter(result.ServerName, p.createLimiter(result.ServerName))
+		p.registry.SetBreaker(result.ServerName, p.createBreaker(result.ServerName))
+		p.registry.SetChaos(result.ServerName, p.createChaos(result.ServerName))
+		
+		// Aggregate resources and prompts exposed by the server
+		p.syncServerFeatures(ctx, result.ServerName, result.ServerPrefix, mcpClient)
 		
 		// Register tools and create handlers
 		for _, tool := range result.Tools {
@@ -97,20 +151,26 @@ func (p *ProxyServer) Initialize(ctx context.Context) error {
 			mcpTool := p.createMCPTool(tool)
 			
 			// Create proxy handler
-			handler := proxy.CreateProxyHandler(mcpClient, tool)
+			handler := proxy.CreateProxyHandler(mcpClient, tool, p.handlerOptions(tool.ServerName))
+			p.registry.SetHandler(tool.PrefixedName, handler)
 			
 			// Register with MCP server
 			p.mcpServer.AddTool(mcpTool, handler)
 			
-			log.Printf("Registered tool: %s", tool.PrefixedName)
+			serverLog.Debug("Registered tool", "server", tool.ServerName, "tool", tool.PrefixedName)
 		}
 	}
 	
-	log.Printf("Successfully registered %d tools from %d servers", totalTools, len(successfulResults))
+	serverLog.Info("Successfully registered tools", "tools", totalTools, "servers", len(successfulResults))
+	
+	// Register tools composed from the proxied ones
+	if err := p.registerVirtualTools(); err != nil {
+		return fmt.Errorf("failed to register virtual tools: %w", err)
+	}
 	
 	// Allow starting with zero tools for dynamic management
 	if totalTools == 0 {
-		log.Printf("Starting with no tools - use server_add to add MCP servers dynamically")
+		proxyLog.Info("Starting with no tools - use server_add to add MCP servers dynamically")
 	}
 	
 	p.initialized = true
@@ -126,18 +186,33 @@ func (p *ProxyServer) Start() error {
 		return fmt.Errorf("server not initialized - call Initialize() first")
 	}
 	
-	log.Println("Starting MCP proxy server...")
+	proxyLog.Info("Starting MCP proxy server...")
 	
 	// Start the MCP server (this blocks)
-	return server.ServeStdio(p.mcpServer)
+	transport := newStdioTransport(p.mcpServer)
+	p.registerResourceHandlers(transport)
+	p.registerCompletionHandlers(transport)
+	p.registerLoggingHandlers(transport)
+	return transport.Serve()
 }
 
 // Shutdown gracefully shuts down the proxy server
+// EnableTracing starts exporting spans of tool calls as configured. Must be
+// called before Initialize so the tool handlers are traced.
+func (p *ProxyServer) EnableTracing() error {
+	tracer, err := tracing.New(p.config.Tracing)
+	if err != nil {
+		return err
+	}
+	p.tracer = tracer
+	return nil
+}
+
 func (p *ProxyServer) Shutdown(ctx context.Context) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	
-	log.Println("Shutting down proxy server...")
+	proxyLog.Info("Shutting down proxy server...")
 	
 	var errors []error
 	
@@ -148,14 +223,39 @@ func (p *ProxyServer) Shutdown(ctx context.Context) error {
 		}
 	}
 	
+	// Export the spans of the last calls
+	if err := p.tracer.Close(); err != nil {
+		errors = append(errors, fmt.Errorf("failed to export traces: %w", err))
+	}
+	
 	if len(errors) > 0 {
 		return fmt.Errorf("errors during shutdown: %v", errors)
 	}
 	
-	log.Println("Proxy server shutdown complete")
+	proxyLog.Info("Proxy server shutdown complete")
 	return nil
 }
 
+// syncServerFeatures discovers the resources and prompts of a connected
+// server, registers them with the proxy and applies the server's log level.
+// Failures are logged rather than returned because tools remain usable
+// without them.
+func (p *ProxyServer) syncServerFeatures(ctx context.Context, serverName, prefix string, mcpClient client.MCPClient) {
+	p.applyServerLogLevel(ctx, serverName, mcpClient)
+	if err := p.refreshServerResources(ctx, serverName, prefix, mcpClient); err != nil {
+		serverLog.Warn("Failed to discover resources", "server", serverName, "error", err)
+	}
+	if err := p.refreshServerPrompts(ctx, serverName, prefix, mcpClient); err != nil {
+		serverLog.Warn("Failed to discover prompts", "server", serverName, "error", err)
+	}
+}
+
+// removeServerFeatures unregisters the resources and prompts of a server
+func (p *ProxyServer) removeServerFeatures(serverName string) {
+	p.removeServerResources(serverName)
+	p.removeServerPrompts(serverName)
+}
+
 // createAndConnectClient creates and connects a client for persistent use
 func (p *ProxyServer) createAndConnectClient(ctx context.Context, serverName string) (client.MCPClient, error) {
 	// Find server config
@@ -187,6 +287,8 @@ func (p *ProxyServer) createAndConnectClient(ctx context.Context, serverName str
 			stdioClient.SetEnvironment(env)
 		}
 		
+		stdioClient.SetStderr(logging.ServerStderr(serverConfig.Name))
+		stdioClient.SetNotificationHandler(p.childNotificationHandler(serverConfig.Name, serverConfig.Prefix, stdioClient))
 		mcpClient = stdioClient
 	default:
 		return nil, fmt.Errorf("unsupported transport: %s", serverConfig.Transport)
@@ -205,14 +307,136 @@ func (p *ProxyServer) createAndConnectClient(ctx context.Context, serverName str
 	return mcpClient, nil
 }
 
+// createLimiter creates the limiter shared by a static server's tools, nil
+// if the server has no limits
+func (p *ProxyServer) createLimiter(serverName string) *proxy.Limiter {
+	for _, serverConfig := range p.config.Servers {
+		if serverConfig.Name == serverName {
+			return proxy.NewLimiter(&serverConfig)
+		}
+	}
+	return nil
+}
+
+// addClient adds the client of a server to the client list
+func (p *ProxyServer) addClient(mcpClient client.MCPClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	
+	p.clients = append(p.clients, mcpClient)
+}
+
+// removeClient drops a server's client from the client list
+func (p *ProxyServer) removeClient(serverName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	
+	clients := make([]client.MCPClient, 0, len(p.clients))
+	for _, c := range p.clients {
+		if c.ServerName() != serverName {
+			clients = append(clients, c)
+		}
+	}
+	p.clients = clients
+}
+
+// replaceClient swaps in the new client of a reconnected server
+func (p *ProxyServer) replaceClient(serverName string, mcpClient client.MCPClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	
+	for i, c := range p.clients {
+		if c.ServerName() == serverName {
+			p.clients[i] = mcpClient
+			break
+		}
+	}
+}
+
+// clientList returns a copy of the client list
+func (p *ProxyServer) clientList() []client.MCPClient {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	
+	return append([]client.MCPClient(nil), p.clients...)
+}
+
+// handlerOptions returns the options for the handlers of a server's tools
+func (p *ProxyServer) handlerOptions(serverName string) proxy.HandlerOptions {
+	opts := p.registry.HandlerOptions(serverName)
+	opts.OnViolation = p.recordViolation
+	opts.Metrics = p.metrics
+	opts.Tracer = p.tracer
+	opts.Dashboard = p.dashboard
+	return opts
+}
+
+// instrument wraps a dynamic server's tool handler with logging, metrics,
+// tracing and the dashboard
+func (p *ProxyServer) instrument(serverName, toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	handler = p.tracer.Instrument(serverName, toolName, p.metrics.Instrument(serverName, toolName, handler))
+	handler = p.dashboard.Instrument(serverName, toolName, handler)
+	return logging.Instrument(serverName, toolName, handler)
+}
+
+// recordViolation records schema violations passed on in warn mode, as a
+// request for arguments and a response for results
+func (p *ProxyServer) recordViolation(tool discovery.RemoteTool, schemaName string, violations []schema.Violation) {
+	direction := "request"
+	if schemaName == proxy.OutputSchema {
+		direction = "response"
+	}
+	p.record(direction, "validation_warning", tool.PrefixedName, tool.ServerName, violations)
+}
+
+// createBreaker creates the circuit breaker in front of a static server, nil
+// if it is disabled
+func (p *ProxyServer) createBreaker(serverName string) *proxy.CircuitBreaker {
+	for _, serverConfig := range p.config.Servers {
+		if serverConfig.Name == serverName {
+			return proxy.NewCircuitBreaker(serverConfig.CircuitBreaker)
+		}
+	}
+	return nil
+}
+
+// createChaos creates the fault injection for a static server's tools, with
+// the configured rules. It exists even without rules so chaos_set can add some.
+func (p *ProxyServer) createChaos(serverName string) *proxy.Chaos {
+	for _, serverConfig := range p.config.Servers {
+		if serverConfig.Name == serverName {
+			return proxy.NewChaos(serverConfig.Chaos)
+		}
+	}
+	return proxy.NewChaos(nil)
+}
+
 // createMCPTool creates an mcp.Tool from a RemoteTool
 func (p *ProxyServer) createMCPTool(remoteTool discovery.RemoteTool) mcp.Tool {
-	// For now, create a simple tool with basic parameters
-	// In a full implementation, we would parse the InputSchema to create proper parameter definitions
+	description := fmt.Sprintf("[%s] %s", remoteTool.ServerName, remoteTool.Description)
+	
+	// Expose the remote input schema as-is so clients see parameter changes
+	var tool mcp.Tool
+	if len(remoteTool.InputSchema) > 0 {
+		tool = mcp.NewToolWithRawSchema(remoteTool.PrefixedName, description, remoteTool.InputSchema)
+	} else {
+		tool = mcp.NewTool(remoteTool.PrefixedName,
+			mcp.WithDescription(description),
+		)
+	}
+	
+	// Pass on the server's behavior hints
+	if annotations := remoteTool.Annotations; annotations != nil {
+		tool.Annotations = mcp.ToolAnnotation{
+			Title:           annotations.Title,
+			ReadOnlyHint:    annotations.ReadOnlyHint,
+			DestructiveHint: annotations.DestructiveHint,
+			IdempotentHint:  annotations.IdempotentHint,
+			OpenWorldHint:   annotations.OpenWorldHint,
+		}
+	}
 	
-	return mcp.NewTool(remoteTool.PrefixedName,
-		mcp.WithDescription(fmt.Sprintf("[%s] %s", remoteTool.ServerName, remoteTool.Description)),
-	)
+	return tool
 }
 
 // GetRegisteredTools returns all registered tools for debugging/info
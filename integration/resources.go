@@ -0,0 +1,176 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-debug/client"
+)
+
+// registerResourceHandlers routes resource requests from the client to the
+// proxy's resource aggregation instead of mcp-go's static resource tables
+func (p *ProxyServer) registerResourceHandlers(t *stdioTransport) {
+	t.Handle("resources/list", p.handleListResources)
+	t.Handle("resources/templates/list", p.handleListResourceTemplates)
+	t.Handle("resources/read", p.handleReadResource)
+	t.Handle("resources/subscribe", p.handleSubscribeResource)
+	t.Handle("resources/unsubscribe", p.handleUnsubscribeResource)
+}
+
+// refreshServerResources (re-)discovers the resources and templates of a
+// server, restores client subscriptions and notifies clients of the change
+func (p *ProxyServer) refreshServerResources(ctx context.Context, serverName, prefix string, mcpClient client.MCPClient) error {
+	capabilities := mcpClient.ServerCapabilities()
+	if !client.HasCapability(capabilities, "resources") {
+		p.removeServerResources(serverName)
+		return nil
+	}
+
+	resources, err := mcpClient.ListResources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	// Templates are optional; servers with only static resources may not implement the method
+	templates, err := mcpClient.ListResourceTemplates(ctx)
+	if err != nil {
+		serverLog.Info("Server did not list resource templates", "server", serverName, "error", err)
+		templates = nil
+	}
+
+	p.resources.SetServerResources(serverName, prefix, mcpClient, resources, templates)
+	serverLog.Info("Registered resources", "server", serverName, "resources", len(resources), "templates", len(templates))
+
+	// Restore subscriptions made against a previous connection
+	if client.HasSubCapability(capabilities, "resources", "subscribe") {
+		for _, uri := range p.resources.ServerSubscriptions(serverName) {
+			if err := mcpClient.SubscribeResource(ctx, uri); err != nil {
+				serverLog.Warn("Failed to restore subscription", "server", serverName, "uri", uri, "error", err)
+			}
+		}
+	}
+
+	p.notifyResourceListChanged()
+	return nil
+}
+
+// removeServerResources drops a server's resources and notifies clients
+func (p *ProxyServer) removeServerResources(serverName string) {
+	if p.resources.RemoveServer(serverName) {
+		serverLog.Info("Removed resources", "server", serverName)
+		p.notifyResourceListChanged()
+	}
+}
+
+// notifyResourceListChanged tells connected clients to re-list resources
+func (p *ProxyServer) notifyResourceListChanged() {
+	if p.mcpServer != nil {
+		p.mcpServer.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+	}
+}
+
+func (p *ProxyServer) handleListResources(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	resources := p.resources.ListResources()
+	if resources == nil {
+		resources = []client.ResourceInfo{}
+	}
+	return map[string]interface{}{"resources": resources}, nil
+}
+
+func (p *ProxyServer) handleListResourceTemplates(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	templates := p.resources.ListResourceTemplates()
+	if templates == nil {
+		templates = []client.ResourceTemplateInfo{}
+	}
+	return map[string]interface{}{"resourceTemplates": templates}, nil
+}
+
+func (p *ProxyServer) handleReadResource(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	uri, err := parseResourceURI(params)
+	if err != nil {
+		return nil, err
+	}
+
+	serverName, mcpClient, originalURI, err := p.resources.Resolve(uri)
+	if err != nil {
+		return nil, newRPCError(errCodeResourceNotFound, "%v", err)
+	}
+
+	result, err := mcpClient.ReadResource(ctx, originalURI)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] %v", serverName, err)
+	}
+
+	// Rewrite content URIs into the proxy namespace
+	contents := make([]mcp.ResourceContents, 0, len(result.Contents))
+	for _, content := range result.Contents {
+		contentURI, _ := p.resources.ToProxyURI(serverName, content.URI)
+		if content.Blob != "" {
+			contents = append(contents, mcp.BlobResourceContents{URI: contentURI, MIMEType: content.MimeType, Blob: content.Blob})
+		} else {
+			contents = append(contents, mcp.TextResourceContents{URI: contentURI, MIMEType: content.MimeType, Text: content.Text})
+		}
+	}
+
+	return mcp.ReadResourceResult{Contents: contents}, nil
+}
+
+func (p *ProxyServer) handleSubscribeResource(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	uri, err := parseResourceURI(params)
+	if err != nil {
+		return nil, err
+	}
+
+	serverName, mcpClient, originalURI, err := p.resources.Resolve(uri)
+	if err != nil {
+		return nil, newRPCError(errCodeResourceNotFound, "%v", err)
+	}
+
+	if !client.HasSubCapability(mcpClient.ServerCapabilities(), "resources", "subscribe") {
+		return nil, newRPCError(errCodeMethodNotFound, "server '%s' does not support resource subscriptions", serverName)
+	}
+
+	if err := mcpClient.SubscribeResource(ctx, originalURI); err != nil {
+		return nil, fmt.Errorf("[%s] %v", serverName, err)
+	}
+
+	p.resources.AddSubscription(uri)
+	return struct{}{}, nil
+}
+
+func (p *ProxyServer) handleUnsubscribeResource(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	uri, err := parseResourceURI(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forget the subscription even if the server is gone
+	p.resources.RemoveSubscription(uri)
+
+	serverName, mcpClient, originalURI, err := p.resources.Resolve(uri)
+	if err != nil {
+		return struct{}{}, nil
+	}
+
+	if client.HasSubCapability(mcpClient.ServerCapabilities(), "resources", "subscribe") {
+		if err := mcpClient.UnsubscribeResource(ctx, originalURI); err != nil {
+			return nil, fmt.Errorf("[%s] %v", serverName, err)
+		}
+	}
+
+	return struct{}{}, nil
+}
+
+// parseResourceURI extracts the uri parameter shared by resource requests
+func parseResourceURI(params json.RawMessage) (string, error) {
+	var request struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil || request.URI == "" {
+		return "", newRPCError(errCodeInvalidParams, "uri is required")
+	}
+	return request.URI, nil
+}
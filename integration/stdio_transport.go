@@ -0,0 +1,262 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-debug/client"
+)
+
+// JSON-RPC error codes used by intercepted handlers
+const (
+	errCodeMethodNotFound   = -32601
+	errCodeInvalidParams    = -32602
+	errCodeInternalError    = -32603
+	errCodeResourceNotFound = -32002
+)
+
+// requestHandler handles an intercepted JSON-RPC request and returns its result
+type requestHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// rpcError is returned by request handlers to control the JSON-RPC error code
+type rpcError struct {
+	Code    int
+	Message string
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// newRPCError creates an error with a specific JSON-RPC error code
+func newRPCError(code int, format string, args ...interface{}) *rpcError {
+	return &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// stdioTransport serves an MCPServer over stdio, handling some methods itself
+// before they reach mcp-go. mcp-go has no support for methods such as
+// resources/subscribe, and its resource templates cannot be removed at
+// runtime, so the proxy answers those requests directly.
+type stdioTransport struct {
+	mcpServer    *server.MCPServer
+	handlers     map[string]requestHandler
+	capabilities map[string]interface{} // advertised on top of mcp-go's own
+	out          *syncWriter
+
+	initIDs map[string]bool // pending initialize request IDs
+	initMu  sync.Mutex
+}
+
+// syncWriter serializes writes so intercepted responses and mcp-go output
+// never interleave on stdout
+type syncWriter struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// newStdioTransport creates a transport for the given MCP server
+func newStdioTransport(mcpServer *server.MCPServer) *stdioTransport {
+	return &stdioTransport{
+		mcpServer:    mcpServer,
+		handlers:     make(map[string]requestHandler),
+		capabilities: make(map[string]interface{}),
+		out:          &syncWriter{w: os.Stdout},
+		initIDs:      make(map[string]bool),
+	}
+}
+
+// Handle registers a handler that takes over a JSON-RPC method from mcp-go
+func (t *stdioTransport) Handle(method string, handler requestHandler) {
+	t.handlers[method] = handler
+}
+
+// AdvertiseCapability adds a server capability to the initialize response.
+// Used for capabilities mcp-go has no option for, such as completions.
+func (t *stdioTransport) AdvertiseCapability(name string, value interface{}) {
+	t.capabilities[name] = value
+}
+
+// Serve serves on stdin/stdout until stdin closes or a shutdown signal arrives
+func (t *stdioTransport) Serve() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	// Messages not handled here are piped through to mcp-go's stdio server
+	pipeReader, pipeWriter := io.Pipe()
+	go t.dispatch(ctx, os.Stdin, pipeWriter)
+
+	stdioServer := server.NewStdioServer(t.mcpServer)
+	return stdioServer.Listen(ctx, pipeReader, serverOutput{t})
+}
+
+// serverOutput receives mcp-go's output and adds the transport's own
+// capabilities to initialize responses on the way to stdout
+type serverOutput struct {
+	t *stdioTransport
+}
+
+func (o serverOutput) Write(p []byte) (int, error) {
+	if _, err := o.t.out.Write(o.t.addCapabilities(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// dispatch reads messages from the client and routes each one either to an
+// intercepted handler or to mcp-go
+func (t *stdioTransport) dispatch(ctx context.Context, in io.Reader, passthrough *io.PipeWriter) {
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if !t.intercept(ctx, line) {
+				if _, werr := passthrough.Write(line); werr != nil {
+					return
+				}
+			}
+		}
+		if err != nil {
+			passthrough.CloseWithError(err)
+			return
+		}
+	}
+}
+
+// intercept handles a message if its method has a registered handler
+func (t *stdioTransport) intercept(ctx context.Context, line []byte) bool {
+	var message struct {
+		ID     json.RawMessage `json:"id,omitempty"`
+		Method string          `json:"method,omitempty"`
+		Params json.RawMessage `json:"params,omitempty"`
+	}
+	if err := json.Unmarshal(line, &message); err != nil || message.Method == "" {
+		return false
+	}
+
+	handler, exists := t.handlers[message.Method]
+	if !exists {
+		if message.Method == "initialize" && message.ID != nil && len(t.capabilities) > 0 {
+			t.initMu.Lock()
+			t.initIDs[string(message.ID)] = true
+			t.initMu.Unlock()
+		}
+		return false
+	}
+
+	// Notifications have no ID and get no response
+	if message.ID == nil {
+		go handler(ctx, message.Params)
+		return true
+	}
+
+	go func() {
+		result, err := handler(ctx, message.Params)
+		t.writeResponse(message.ID, message.Method, result, err)
+	}()
+	return true
+}
+
+// writeResponse writes a JSON-RPC result or error for an intercepted request
+func (t *stdioTransport) writeResponse(id json.RawMessage, method string, result interface{}, err error) {
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+	}
+
+	if err != nil {
+		code := errCodeInternalError
+		if rpcErr, ok := err.(*rpcError); ok {
+			code = rpcErr.Code
+		}
+		response["error"] = client.JSONRPCError{Code: code, Message: err.Error()}
+	} else {
+		if result == nil {
+			result = struct{}{}
+		}
+		response["result"] = result
+	}
+
+	responseBytes, marshalErr := json.Marshal(response)
+	if marshalErr != nil {
+		transportLog.Error("Failed to marshal response", "method", method, "error", marshalErr)
+		return
+	}
+
+	if _, writeErr := t.out.Write(append(responseBytes, '\n')); writeErr != nil {
+		transportLog.Error("Failed to write response", "method", method, "error", writeErr)
+	}
+}
+
+// addCapabilities merges the advertised capabilities into a response to a
+// pending initialize request. Any other message is returned unchanged.
+func (t *stdioTransport) addCapabilities(line []byte) []byte {
+	t.initMu.Lock()
+	pending := len(t.initIDs) > 0
+	t.initMu.Unlock()
+	if !pending {
+		return line
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(line, &response); err != nil || response["result"] == nil {
+		return line
+	}
+
+	id := string(response["id"])
+	t.initMu.Lock()
+	isInitialize := t.initIDs[id]
+	delete(t.initIDs, id)
+	t.initMu.Unlock()
+	if !isInitialize {
+		return line
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(response["result"], &result); err != nil {
+		return line
+	}
+	capabilities, _ := result["capabilities"].(map[string]interface{})
+	if capabilities == nil {
+		capabilities = make(map[string]interface{})
+	}
+	for name, value := range t.capabilities {
+		capabilities[name] = value
+	}
+	result["capabilities"] = capabilities
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		transportLog.Error("Failed to add capabilities to initialize response", "error", err)
+		return line
+	}
+	response["result"] = resultBytes
+
+	rewritten, err := json.Marshal(response)
+	if err != nil {
+		transportLog.Error("Failed to add capabilities to initialize response", "error", err)
+		return line
+	}
+	return append(rewritten, '\n')
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-debug/config"
+	"mcp-debug/proxy"
+)
+
+// registerVirtualTools registers the virtual tools defined in the config.
+// Their names are reserved so servers added later cannot shadow them.
+func (p *ProxyServer) registerVirtualTools() error {
+	for _, def := range p.config.VirtualTools {
+		if p.registry.IsReserved(def.Name) {
+			return fmt.Errorf("virtual tool '%s' collides with built-in tool '%s'", def.Name, def.Name)
+		}
+		if tool, exists := p.registry.GetTool(def.Name); exists {
+			return fmt.Errorf("virtual tool '%s' collides with tool '%s' from server '%s'",
+				def.Name, tool.OriginalName, tool.ServerName)
+		}
+		// A server added later may still provide a missing step tool
+		for i, step := range def.Steps {
+			if _, exists := p.registry.GetTool(step.Tool); !exists {
+				serverLog.Warn("Virtual tool step calls a tool that is not registered",
+					"tool", def.Name, "step", step.StepID(i), "step_tool", step.Tool)
+			}
+		}
+		p.registry.ReserveName(def.Name)
+
+		p.mcpServer.AddTool(proxy.CreateVirtualTool(def), p.createVirtualToolHandler(def))
+		serverLog.Info("Registered virtual tool", "tool", def.Name, "steps", len(def.Steps))
+	}
+	return nil
+}
+
+// createVirtualToolHandler runs a virtual tool and records the call as a
+// single unit, without the individual steps
+func (p *ProxyServer) createVirtualToolHandler(def config.VirtualToolConfig) server.ToolHandlerFunc {
+	handler := proxy.CreateVirtualToolHandler(def, p.callProxiedTool)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		p.record("request", "tool_call", def.Name, "virtual", request)
+		result, err := handler(ctx, request)
+		if err == nil {
+			p.record("response", "tool_call", def.Name, "virtual", result)
+		}
+		return result, err
+	}
+}
+
+// virtualStepKey marks the context of a call made by a virtual tool step
+type virtualStepKey struct{}
+
+// isVirtualStep reports whether a call is a step of a virtual tool
+func isVirtualStep(ctx context.Context) bool {
+	return ctx.Value(virtualStepKey{}) != nil
+}
+
+// callProxiedTool calls a proxied tool by its exposed name, through the same
+// handler a client's call goes through
+func (p *ProxyServer) callProxiedTool(ctx context.Context, toolName string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	handler, exists := p.registry.GetHandler(toolName)
+	if !exists {
+		return nil, fmt.Errorf("tool not found: %s", toolName)
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Name = toolName
+	request.Params.Arguments = args
+	return handler(context.WithValue(ctx, virtualStepKey{}, true), request)
+}
+
+// record passes a message to the recorder, if one is set
+func (p *ProxyServer) record(direction, messageType, toolName, serverName string, message interface{}) {
+	if p.recorder != nil {
+		p.recorder(direction, messageType, toolName, serverName, message)
+	}
+}
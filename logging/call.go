@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxStderrLine is the longest line of server stderr kept in one entry
+const maxStderrLine = 64 << 10
+
+// call identifies the tool call being handled
+type call struct {
+	id     string
+	server string
+	tool   string
+}
+
+// callKey is the context key of the call being handled
+type callKey struct{}
+
+func callFromContext(ctx context.Context) *call {
+	if ctx == nil {
+		return nil
+	}
+	c, _ := ctx.Value(callKey{}).(*call)
+	return c
+}
+
+// RequestID returns the request ID of the call in ctx, empty if there is none
+func RequestID(ctx context.Context) string {
+	if c := callFromContext(ctx); c != nil {
+		return c.id
+	}
+	return ""
+}
+
+func newRequestID() string {
+	var id [4]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// Instrument wraps a tool handler so that entries logged with the call's
+// context carry its server, tool and a new request ID, and logs the call:
+// at debug level, or higher if it fails
+func Instrument(serverName, toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	logger := For(ComponentCall)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = context.WithValue(ctx, callKey{}, &call{id: newRequestID(), server: serverName, tool: toolName})
+		logger.DebugContext(ctx, "Tool call started")
+
+		start := time.Now()
+		result, err := handler(ctx, request)
+		elapsed := float64(time.Since(start).Microseconds()) / 1000
+
+		switch {
+		case err != nil:
+			logger.WarnContext(ctx, "Tool call failed", "duration_ms", elapsed, "error", err)
+		case result != nil && result.IsError:
+			logger.InfoContext(ctx, "Tool call returned an error", "duration_ms", elapsed, "error", resultText(result))
+		default:
+			logger.DebugContext(ctx, "Tool call finished", "duration_ms", elapsed)
+		}
+		return result, err
+	}
+}
+
+// resultText returns the first text content of a result
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
+
+// ServerStderr returns a writer logging each line a server's process writes
+// to stderr as a debug entry of the server component
+func ServerStderr(serverName string) io.Writer {
+	return &stderrWriter{logger: For(ComponentServer).With(KeyServer, serverName)}
+}
+
+type stderrWriter struct {
+	logger  *slog.Logger
+	pending []byte
+	mu      sync.Mutex
+}
+
+func (w *stderrWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			break
+		}
+		w.log(w.pending[:end])
+		w.pending = w.pending[end+1:]
+	}
+	if len(w.pending) > maxStderrLine {
+		w.log(w.pending)
+		w.pending = nil
+	}
+	return len(p), nil
+}
+
+func (w *stderrWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) > 0 {
+		w.logger.Debug("Server stderr", "line", string(line))
+	}
+}
@@ -0,0 +1,435 @@
+// Package logging is the proxy's structured, leveled log. Every entry names
+// the component that wrote it, and entries about a server or a tool call
+// carry the server, tool and request ID. Levels can be set per component and
+// per server while the proxy runs, and a server's entries can also go to a
+// log file of its own.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mcp-debug/config"
+	"mcp-debug/redact"
+)
+
+// Components, the "component" field of every entry
+const (
+	ComponentProxy      = "proxy"      // startup, configuration and shutdown
+	ComponentServer     = "server"     // server connections, discovery and registration
+	ComponentCall       = "call"       // tool calls
+	ComponentValidation = "validation" // schema violations
+	ComponentChaos      = "chaos"      // injected faults
+	ComponentTracing    = "tracing"    // span export
+	ComponentTransport  = "transport"  // the client connection and recording
+)
+
+// Components lists every component
+var Components = []string{
+	ComponentProxy, ComponentServer, ComponentCall, ComponentValidation,
+	ComponentChaos, ComponentTracing, ComponentTransport,
+}
+
+// Fields the log itself looks at
+const (
+	KeyComponent = "component"
+	KeyServer    = "server"
+	KeyTool      = "tool"
+	KeyRequestID = "request_id"
+)
+
+// state is shared by the loggers of all components
+type state struct {
+	format     string
+	level      slog.Level            // used unless a component or server has its own
+	components map[string]slog.Level // component -> level
+	servers    map[string]slog.Level // server name -> level, before the component's
+	minimum    slog.LevelVar         // lowest level in use, checked before anything else
+
+	main          *output
+	serverOutputs map[string]*output // server name -> its own log file
+	maxBytes      int
+	maxFiles      int
+	mu            sync.RWMutex
+}
+
+// output is a destination of entries with the handler formatting them
+type output struct {
+	file    *rotatingFile // nil for stderr
+	handler slog.Handler
+}
+
+var std = newState()
+
+func newState() *state {
+	s := &state{
+		format:        config.LogFormatLogfmt,
+		level:         defaultLevel(""),
+		components:    make(map[string]slog.Level),
+		servers:       make(map[string]slog.Level),
+		serverOutputs: make(map[string]*output),
+		maxFiles:      config.DefaultLogMaxFiles,
+	}
+	s.main = s.newOutput(nil, os.Stderr)
+	s.updateMinimum()
+	return s
+}
+
+// defaultLevel returns the configured default level, or debug if MCP_DEBUG
+// is set
+func defaultLevel(configured string) slog.Level {
+	if debug, _ := strconv.ParseBool(os.Getenv("MCP_DEBUG")); debug {
+		return slog.LevelDebug
+	}
+	if level, err := ParseLevel(configured); err == nil {
+		return level
+	}
+	return slog.LevelInfo
+}
+
+// ParseLevel parses one of config.ProxyLogLevels
+func ParseLevel(name string) (slog.Level, error) {
+	switch name {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level '%s' (use %s)", name, strings.Join(config.ProxyLogLevels, ", "))
+}
+
+// levelName is the inverse of ParseLevel
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// newOutput creates an output writing entries to w in the current format.
+// Callers must hold s.mu or own s exclusively.
+func (s *state) newOutput(file *rotatingFile, w io.Writer) *output {
+	// Levels are checked before entries get here, so the handler takes all
+	options := &slog.HandlerOptions{Level: slog.Level(math.MinInt)}
+	if s.format == config.LogFormatJSON {
+		return &output{file: file, handler: slog.NewJSONHandler(w, options)}
+	}
+	return &output{file: file, handler: slog.NewTextHandler(w, options)}
+}
+
+// updateMinimum recomputes the lowest level in use. Callers must hold s.mu.
+func (s *state) updateMinimum() {
+	minimum := s.level
+	for _, level := range s.components {
+		minimum = min(minimum, level)
+	}
+	for _, level := range s.servers {
+		minimum = min(minimum, level)
+	}
+	s.minimum.Set(minimum)
+}
+
+// levelFor returns the level for entries of a component about a server
+func (s *state) levelFor(component, serverName string) slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if level, exists := s.servers[serverName]; exists && serverName != "" {
+		return level
+	}
+	if level, exists := s.components[component]; exists {
+		return level
+	}
+	return s.level
+}
+
+// write writes an entry to the main log and to its server's log file
+func (s *state) write(ctx context.Context, serverName string, entry slog.Record) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err := s.main.handler.Handle(ctx, entry)
+	if out, exists := s.serverOutputs[serverName]; exists && serverName != "" {
+		if serverErr := out.handler.Handle(ctx, entry); err == nil {
+			err = serverErr
+		}
+	}
+	return err
+}
+
+// Open sends the log to a file instead of stderr, with secrets redacted, and
+// routes the standard log package through it
+func Open(path string) error {
+	file, err := openRotatingFile(path)
+	if err != nil {
+		return err
+	}
+
+	std.mu.Lock()
+	file.setLimits(std.maxBytes, std.maxFiles)
+	previous := std.main
+	std.main = std.newOutput(file, redact.NewWriter(file, redact.Default))
+	std.mu.Unlock()
+
+	if previous.file != nil {
+		previous.file.Close()
+	}
+	slog.SetDefault(For(ComponentProxy))
+	return nil
+}
+
+// Configure applies the logging section of the config: the format, levels,
+// rotation and the servers' own log files
+func Configure(cfg *config.ProxyConfig) error {
+	settings := cfg.Logging
+	components := make(map[string]slog.Level, len(settings.Components))
+	for component, name := range settings.Components {
+		if !isComponent(component) {
+			return fmt.Errorf("unknown log component '%s' (use %s)", component, strings.Join(Components, ", "))
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		components[component] = level
+	}
+	servers := make(map[string]slog.Level, len(settings.Servers))
+	for serverName, name := range settings.Servers {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		servers[serverName] = level
+	}
+
+	// Open the servers' files before changing anything, so a failure leaves
+	// the log as it was
+	files := make(map[string]*rotatingFile)
+	for _, server := range cfg.Servers {
+		if server.LogFile == "" {
+			continue
+		}
+		file, err := openRotatingFile(server.LogFile)
+		if err != nil {
+			for _, opened := range files {
+				opened.Close()
+			}
+			return fmt.Errorf("server %s: %w", server.Name, err)
+		}
+		file.setLimits(settings.MaxBytes, settings.GetMaxFiles())
+		files[server.Name] = file
+	}
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.format = settings.Format
+	if std.format == "" {
+		std.format = config.LogFormatLogfmt
+	}
+	std.level = defaultLevel(settings.Level)
+	std.components = components
+	std.servers = servers
+	std.maxBytes = settings.MaxBytes
+	std.maxFiles = settings.GetMaxFiles()
+	std.updateMinimum()
+
+	if std.main.file != nil {
+		std.main.file.setLimits(std.maxBytes, std.maxFiles)
+		std.main = std.newOutput(std.main.file, redact.NewWriter(std.main.file, redact.Default))
+	} else {
+		std.main = std.newOutput(nil, os.Stderr)
+	}
+
+	for _, out := range std.serverOutputs {
+		out.file.Close()
+	}
+	std.serverOutputs = make(map[string]*output, len(files))
+	for serverName, file := range files {
+		std.serverOutputs[serverName] = std.newOutput(file, redact.NewWriter(file, redact.Default))
+	}
+	return nil
+}
+
+// Close closes the log files; later entries go to stderr
+func Close() error {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	var err error
+	if std.main.file != nil {
+		err = std.main.file.Close()
+		std.main = std.newOutput(nil, os.Stderr)
+	}
+	for _, out := range std.serverOutputs {
+		out.file.Close()
+	}
+	std.serverOutputs = make(map[string]*output)
+	return err
+}
+
+func isComponent(name string) bool {
+	for _, component := range Components {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLevel sets the default level, the level of a component, or the level of
+// a server's entries, whichever is named. An empty level removes a
+// component's or server's own level.
+func SetLevel(component, serverName, name string) error {
+	if component != "" && serverName != "" {
+		return fmt.Errorf("set the level of a component or a server, not both")
+	}
+	if component != "" && !isComponent(component) {
+		return fmt.Errorf("unknown log component '%s' (use %s)", component, strings.Join(Components, ", "))
+	}
+
+	var level slog.Level
+	if name != "" || (component == "" && serverName == "") {
+		var err error
+		if level, err = ParseLevel(name); err != nil {
+			return err
+		}
+	}
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	switch {
+	case component != "" && name == "":
+		delete(std.components, component)
+	case component != "":
+		std.components[component] = level
+	case serverName != "" && name == "":
+		delete(std.servers, serverName)
+	case serverName != "":
+		std.servers[serverName] = level
+	default:
+		std.level = level
+	}
+	std.updateMinimum()
+	return nil
+}
+
+// Levels describes the default level and the component and server levels
+func Levels() string {
+	std.mu.RLock()
+	defer std.mu.RUnlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "default: %s\n", levelName(std.level))
+	fmt.Fprintf(&b, "components: %s\n", describeLevels(std.components))
+	fmt.Fprintf(&b, "servers: %s", describeLevels(std.servers))
+	return b.String()
+}
+
+func describeLevels(levels map[string]slog.Level) string {
+	if len(levels) == 0 {
+		return "(default)"
+	}
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, levelName(levels[name])))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// For returns the logger of a component
+func For(component string) *slog.Logger {
+	return slog.New(&handler{component: component})
+}
+
+// handler adds the component, and the server, tool and request ID of the
+// call in the context, then applies the levels before writing an entry
+type handler struct {
+	component string
+	attrs     []slog.Attr
+	prefix    string // group names as a key prefix, e.g. "request."
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= std.minimum.Level()
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		clone.attrs = append(clone.attrs, h.qualify(attr))
+	}
+	return &clone
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+func (h *handler) qualify(attr slog.Attr) slog.Attr {
+	if h.prefix != "" {
+		attr.Key = h.prefix + attr.Key
+	}
+	return attr
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, h.qualify(attr))
+		return true
+	})
+
+	// Fields given explicitly win over those of the call in the context
+	fields := map[string]string{}
+	if call := callFromContext(ctx); call != nil {
+		fields[KeyServer] = call.server
+		fields[KeyTool] = call.tool
+		fields[KeyRequestID] = call.id
+	}
+	rest := attrs[:0]
+	for _, attr := range attrs {
+		switch attr.Key {
+		case KeyServer, KeyTool, KeyRequestID:
+			fields[attr.Key] = attr.Value.String()
+		default:
+			rest = append(rest, attr)
+		}
+	}
+
+	if r.Level < std.levelFor(h.component, fields[KeyServer]) {
+		return nil
+	}
+
+	entry := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	entry.AddAttrs(slog.String(KeyComponent, h.component))
+	for _, key := range []string{KeyServer, KeyTool, KeyRequestID} {
+		if fields[key] != "" {
+			entry.AddAttrs(slog.String(key, fields[key]))
+		}
+	}
+	entry.AddAttrs(rest...)
+	return std.write(ctx, fields[KeyServer], entry)
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rotatingFile is a log file that is renamed to path.1 once it would grow
+// past maxBytes, moving older files up to path.<maxFiles>
+type rotatingFile struct {
+	path     string
+	file     *os.File
+	size     int64
+	maxBytes int64 // 0 never rotates
+	maxFiles int
+	mu       sync.Mutex
+}
+
+func openRotatingFile(path string) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	f := &rotatingFile{path: path, maxFiles: 1}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) setLimits(maxBytes, maxFiles int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maxBytes = int64(maxBytes)
+	f.maxFiles = max(maxFiles, 1)
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate moves the file to path.1, dropping the oldest rotated file, and
+// starts a new one. Callers must hold f.mu.
+func (f *rotatingFile) rotate() error {
+	f.file.Close()
+	f.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxFiles))
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	// If the rename fails, entries go on into the same file
+	os.Rename(f.path, f.path+".1")
+	return f.open()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
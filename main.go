@@ -7,16 +7,21 @@ import (
 	"log"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	
+	"mcp-debug/bench"
 	"mcp-debug/config"
+	"mcp-debug/conform"
+	"mcp-debug/fuzz"
 	"mcp-debug/integration"
+	"mcp-debug/logging"
 	"mcp-debug/playback"
+	"mcp-debug/redact"
 )
 
 const Version = "1.0.0"
@@ -26,6 +31,9 @@ var (
 	GitCommit = "unknown"
 )
 
+// logger is the log of the proxy modes
+var logger = logging.For(logging.ComponentProxy)
+
 // setupLogging configures logging for stdio MCP mode
 func setupLogging(logFile string) error {
 	// Default log file if not specified
@@ -33,23 +41,12 @@ func setupLogging(logFile string) error {
 		logFile = "/tmp/mcp-proxy.log"
 	}
 	
-	// Ensure directory exists
-	dir := filepath.Dir(logFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
-	}
-	
-	// Open log file
-	f, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+	// Log to the file, with secrets redacted; the config adds its settings later
+	if err := logging.Open(logFile); err != nil {
+		return err
 	}
-	
-	// Set log output to file
-	log.SetOutput(f)
-	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
-	log.Printf("=== MCP Proxy Server Started ===")
-	log.Printf("Logging to: %s", logFile)
+	logger.Info("=== MCP Proxy Server Started ===", "version", Version)
+	logger.Info("Logging to file", "path", logFile)
 	
 	return nil
 }
@@ -98,7 +95,8 @@ func main() {
 		
 		// Use dynamic proxy with management tools
 		if err := runDynamicProxyWithManagement(*configPath, *recordFile); err != nil {
-			log.Fatalf("Dynamic proxy server failed: %v", err)
+			logger.Error("Dynamic proxy server failed", "error", err)
+			os.Exit(1)
 		}
 		return
 	}
@@ -124,6 +122,15 @@ func main() {
 		case "tools":
 			handleToolsCommand()
 			return
+		case "conform":
+			handleConformCommand()
+			return
+		case "fuzz":
+			handleFuzzCommand()
+			return
+		case "bench":
+			handleBenchCommand()
+			return
 		default:
 			if strings.HasPrefix(os.Args[1], "-") {
 				fmt.Printf("Unknown flag: %s\n", os.Args[1])
@@ -182,33 +189,51 @@ func runDynamicProxyWithManagement(configPath, recordFile string) error {
 	ctx := context.Background()
 	
 	// Load configuration
-	log.Printf("Loading configuration from: %s", configPath)
+	logger.Info("Loading configuration", "path", configPath)
 	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 	
-	log.Printf("Configuration loaded: %d servers configured", len(cfg.Servers))
+	logger.Info("Configuration loaded", "servers", len(cfg.Servers))
+	
+	// Redact configured secrets from logs and recordings
+	if err := redact.Default.Configure(cfg); err != nil {
+		return fmt.Errorf("failed to configure redaction: %w", err)
+	}
+	
+	// Apply the log format, levels and files
+	if err := logging.Configure(cfg); err != nil {
+		return fmt.Errorf("failed to configure logging: %w", err)
+	}
 	
 	// Create dynamic wrapper
 	wrapper := integration.NewDynamicWrapper(cfg)
 	
 	// Enable recording if specified
 	if recordFile != "" {
-		log.Printf("Recording JSON-RPC traffic to: %s", recordFile)
+		logger.Info("Recording JSON-RPC traffic", "path", recordFile)
 		if err := wrapper.EnableRecording(recordFile); err != nil {
 			return fmt.Errorf("failed to enable recording: %w", err)
 		}
 	}
 	
+	// Export traces if configured
+	if cfg.Tracing.Enabled() {
+		logger.Info("Exporting traces", "endpoint", cfg.Tracing.Endpoint, "file", cfg.Tracing.File)
+		if err := wrapper.EnableTracing(); err != nil {
+			return fmt.Errorf("failed to enable tracing: %w", err)
+		}
+	}
+	
 	// Initialize with static servers
-	log.Println("Initializing proxy server...")
+	logger.Info("Initializing proxy server...")
 	if err := wrapper.Initialize(ctx); err != nil {
 		// Allow starting with no tools for dynamic management
 		if !strings.Contains(err.Error(), "no tools were successfully discovered") {
 			return fmt.Errorf("failed to initialize: %w", err)
 		}
-		log.Println("Starting with no initial servers - use server_add to add servers dynamically")
+		logger.Info("Starting with no initial servers - use server_add to add servers dynamically")
 	}
 	
 	// Start the server
@@ -217,7 +242,7 @@ func runDynamicProxyWithManagement(configPath, recordFile string) error {
 
 // runProxyServer runs the MCP proxy server with the given configuration
 func runDynamicProxyServer(configPath string) error {
-	log.Printf("Loading configuration from: %s", configPath)
+	logger.Info("Loading configuration", "path", configPath)
 	
 	// Load configuration
 	cfg, err := config.LoadConfig(configPath)
@@ -225,7 +250,17 @@ func runDynamicProxyServer(configPath string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 	
-	log.Printf("Configuration loaded: %d servers configured", len(cfg.Servers))
+	logger.Info("Configuration loaded", "servers", len(cfg.Servers))
+	
+	// Redact configured secrets from logs and recordings
+	if err := redact.Default.Configure(cfg); err != nil {
+		return fmt.Errorf("failed to configure redaction: %w", err)
+	}
+	
+	// Apply the log format, levels and files
+	if err := logging.Configure(cfg); err != nil {
+		return fmt.Errorf("failed to configure logging: %w", err)
+	}
 	
 	// Create dynamic proxy server
 	proxyServer := integration.NewDynamicProxyServer(&cfg.Proxy)
@@ -240,7 +275,7 @@ func runDynamicProxyServer(configPath string) error {
 	
 	go func() {
 		<-sigChan
-		log.Printf("Shutting down...")
+		logger.Info("Shutting down...")
 		cancel()
 		proxyServer.Shutdown()
 	}()
@@ -249,13 +284,13 @@ func runDynamicProxyServer(configPath string) error {
 	go func() {
 		for _, serverConfig := range cfg.Servers {
 			if err := proxyServer.ConnectToServer(ctx, serverConfig); err != nil {
-				log.Printf("Failed to connect to server %s: %v", serverConfig.Name, err)
+				logger.Error("Failed to connect to server", "server", serverConfig.Name, "error", err)
 			}
 		}
 	}()
 	
 	// Start the MCP server (this will block)
-	log.Printf("Starting dynamic MCP proxy server...")
+	logger.Info("Starting dynamic MCP proxy server...")
 	return proxyServer.Serve()
 }
 
@@ -263,19 +298,37 @@ func runProxyServer(configPath string) error {
 	ctx := context.Background()
 	
 	// Load configuration
-	log.Printf("Loading configuration from: %s", configPath)
+	logger.Info("Loading configuration", "path", configPath)
 	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 	
-	log.Printf("Configuration loaded: %d servers configured", len(cfg.Servers))
+	logger.Info("Configuration loaded", "servers", len(cfg.Servers))
+	
+	// Redact configured secrets from logs and recordings
+	if err := redact.Default.Configure(cfg); err != nil {
+		return fmt.Errorf("failed to configure redaction: %w", err)
+	}
+	
+	// Apply the log format, levels and files
+	if err := logging.Configure(cfg); err != nil {
+		return fmt.Errorf("failed to configure logging: %w", err)
+	}
 	
 	// Create proxy server
 	proxyServer := integration.NewProxyServer(cfg)
 	
+	// Export traces if configured
+	if cfg.Tracing.Enabled() {
+		logger.Info("Exporting traces", "endpoint", cfg.Tracing.Endpoint, "file", cfg.Tracing.File)
+		if err := proxyServer.EnableTracing(); err != nil {
+			return fmt.Errorf("failed to enable tracing: %w", err)
+		}
+	}
+	
 	// Initialize proxy server (connect to remotes and discover tools)
-	log.Println("Initializing proxy server...")
+	logger.Info("Initializing proxy server...")
 	if err := proxyServer.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize proxy server: %w", err)
 	}
@@ -283,14 +336,14 @@ func runProxyServer(configPath string) error {
 	// Set up graceful shutdown
 	// TODO: Add signal handling for graceful shutdown
 	defer func() {
-		log.Println("Shutting down...")
+		logger.Info("Shutting down...")
 		if err := proxyServer.Shutdown(ctx); err != nil {
-			log.Printf("Shutdown error: %v", err)
+			logger.Error("Shutdown failed", "error", err)
 		}
 	}()
 	
 	// Start the proxy server (this blocks)
-	log.Println("Proxy server initialized successfully. Starting MCP server...")
+	logger.Info("Proxy server initialized successfully. Starting MCP server...")
 	return proxyServer.Start()
 }
 
@@ -336,6 +389,9 @@ func printUsage() {
     %s env              Environment variable management
     %s test             Test MCP tools directly
     %s tools            Tool interface commands
+    %s conform -- <server command>   Check an MCP server against the spec
+    %s fuzz -- <server command>      Fuzz an MCP server's tools
+    %s bench --call <tool> -- <server command>   Load test an MCP server
     
     For MCP client usage (proxy mode):
     1. Create a configuration file:
@@ -365,12 +421,12 @@ func printUsage() {
     - hello_world: Say hello to someone
     
     Environment Variables:
-    - MCP_DEBUG=1: Enable debug logging
+    - MCP_DEBUG=1: Enable debug logging (default level debug)
     - MCP_CONFIG_PATH: Path to configuration file
     
     For more information about MCP:
     https://modelcontextprotocol.io/
-`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
 
 // handleVersionCommand shows version information
@@ -597,6 +653,205 @@ Example:
 	}
 }
 
+// handleConformCommand checks a server's protocol conformance and exits
+// with status 1 if it found errors, so it can gate servers in CI
+func handleConformCommand() {
+	flags := flag.NewFlagSet("conform", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "Write the report as JSON")
+	timeout := flags.Duration("timeout", 10*time.Second, "How long to wait for each response")
+	strict := flags.Bool("strict", false, "Also fail on warnings")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Conformance Checking:
+    %s conform [--json] [--timeout 10s] [--strict] -- <server command> [args...]
+    
+Example:
+    %s conform -- ./math-server
+    %s conform --json -- python server.py > report.json
+`, os.Args[0], os.Args[0], os.Args[0])
+	}
+	flags.Parse(os.Args[2:])
+	
+	command := flags.Args()
+	if len(command) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	
+	report := conform.Run(context.Background(), conform.Options{
+		Command: command,
+		Timeout: *timeout,
+	})
+	
+	if *jsonOutput {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	
+	if report.Summary.Errors > 0 || (*strict && report.Summary.Warnings > 0) {
+		os.Exit(1)
+	}
+}
+
+// handleFuzzCommand fuzzes a server's tools and exits with status 1 if it
+// found failures
+func handleFuzzCommand() {
+	flags := flag.NewFlagSet("fuzz", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "Write the report as JSON")
+	timeout := flags.Duration("timeout", 10*time.Second, "How long to wait for each call before reporting a hang")
+	tools := flags.String("tools", "", "Comma-separated tools to fuzz (default all)")
+	outDir := flags.String("out", "fuzz-failures", "Directory for recordings of crashes and hangs")
+	readOnly := flags.Bool("read-only", false, "Only fuzz tools annotated as read-only")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Tool Fuzzing:
+    %s fuzz [--json] [--timeout 10s] [--tools a,b] [--out dir] [--read-only] -- <server command> [args...]
+    
+Fuzzing calls the tools for real; point it at a server with nothing to lose,
+or use --read-only.
+    
+Example:
+    %s fuzz --tools search,fetch -- ./my-server
+    %s --playback-client fuzz-failures/search-crash-1.jsonl | ./my-server
+`, os.Args[0], os.Args[0], os.Args[0])
+	}
+	flags.Parse(os.Args[2:])
+	
+	command := flags.Args()
+	if len(command) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	
+	var toolNames []string
+	if *tools != "" {
+		toolNames = strings.Split(*tools, ",")
+	}
+	
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	
+	report, err := fuzz.Run(ctx, fuzz.Options{
+		Command:  command,
+		Timeout:  *timeout,
+		Tools:    toolNames,
+		OutDir:   *outDir,
+		ReadOnly: *readOnly,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Fuzzing failed: %v\n", err)
+		os.Exit(2)
+	}
+	
+	if *jsonOutput {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	
+	if len(report.Findings) > 0 {
+		os.Exit(1)
+	}
+}
+
+// callFlags collects repeated --call flags
+type callFlags []string
+
+func (c *callFlags) String() string { return strings.Join(*c, " ") }
+func (c *callFlags) Set(value string) error { *c = append(*c, value); return nil }
+
+// handleBenchCommand load tests a server and exits with status 1 if it
+// regressed against a baseline
+func handleBenchCommand() {
+	var calls callFlags
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	flags.Var(&calls, "call", "Call to include in the mix as tool[:weight][=json-args] (repeatable)")
+	concurrency := flags.Int("concurrency", 1, "Calls in flight at most")
+	rate := flags.Float64("rate", 0, "Calls started per second (default: back to back)")
+	duration := flags.Duration("duration", 10*time.Second, "How long to run")
+	requests := flags.Int("requests", 0, "Stop after this many calls (default: no limit)")
+	timeout := flags.Duration("timeout", 30*time.Second, "How long to wait for each call")
+	jsonOutput := flags.Bool("json", false, "Write the report as JSON")
+	baselinePath := flags.String("baseline", "", "Report from an earlier --json run to compare with")
+	tolerance := flags.Float64("tolerance", 0.2, "Allowed slowdown against the baseline (0.2 is 20%)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Benchmarking:
+    %s bench --call <tool>[:weight][=json] [--call ...] [--concurrency N | --rate R]
+        [--duration 10s] [--requests N] [--json] [--baseline old.json] -- <server command> [args...]
+    
+Example:
+    %s bench --call 'search:3={"query":"go"}' --call fetch --concurrency 8 -- ./my-server
+    %s bench --call add='{"a":1,"b":2}' --rate 100 --duration 1m -- ./mcp-debug --proxy --config config.yaml
+`, os.Args[0], os.Args[0], os.Args[0])
+	}
+	flags.Parse(os.Args[2:])
+	
+	command := flags.Args()
+	if len(command) == 0 || len(calls) == 0 || *concurrency < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	
+	opts := bench.Options{
+		Command:     command,
+		Concurrency: *concurrency,
+		Rate:        *rate,
+		Duration:    *duration,
+		Requests:    *requests,
+		Timeout:     *timeout,
+	}
+	for _, spec := range calls {
+		call, err := bench.ParseCall(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		opts.Calls = append(opts.Calls, call)
+	}
+	
+	var baseline *bench.Report
+	if *baselinePath != "" {
+		var err error
+		if baseline, err = bench.LoadReport(*baselinePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	
+	report, err := bench.Run(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Benchmark failed: %v\n", err)
+		os.Exit(2)
+	}
+	
+	if *jsonOutput {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	
+	if baseline != nil {
+		regressions := report.Regressions(baseline, *tolerance)
+		for _, regression := range regressions {
+			fmt.Fprintf(os.Stderr, "Regression: %s\n", regression)
+		}
+		if len(regressions) > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
 // runPlaybackClient runs the playback client mode
 func runPlaybackClient(recordingFile string) error {
 	log.SetOutput(os.Stderr) // Ensure logs go to stderr, not stdout
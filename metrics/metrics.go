@@ -0,0 +1,321 @@
+// Package metrics collects per-server and per-tool call metrics and serves
+// them in the Prometheus text format.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Error kinds, the "kind" label of mcp_proxy_tool_errors_total
+const (
+	ErrorTool             = "tool"              // result with isError set by the server
+	ErrorProtocol         = "protocol"          // JSON-RPC error or unreadable response
+	ErrorTimeout          = "timeout"           // no response in time
+	ErrorConnection       = "connection"        // server disconnected or connection lost
+	ErrorInvalidArguments = "invalid_arguments" // rejected by input validation
+	ErrorInvalidResult    = "invalid_result"    // rejected by output validation
+	ErrorLimited          = "limited"           // gave up waiting for call limits
+	ErrorCircuitOpen      = "circuit_open"      // refused by the circuit breaker
+)
+
+// latencyBuckets are the upper bounds of the latency histogram in seconds
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics holds the counters of a proxy. A nil Metrics records nothing.
+type Metrics struct {
+	tools    map[toolKey]*toolSeries
+	restarts map[string]int64
+	states   func() map[string]bool // server name -> connected
+	mu       sync.Mutex
+}
+
+type toolKey struct {
+	server string
+	tool   string
+}
+
+type toolSeries struct {
+	calls         int64
+	inFlight      int64
+	errors        map[string]int64
+	requestBytes  int64
+	responseBytes int64
+	buckets       []int64 // non-cumulative counts per latency bucket, +Inf last
+	latencySum    float64
+}
+
+// New creates an empty set of metrics
+func New() *Metrics {
+	return &Metrics{
+		tools:    make(map[toolKey]*toolSeries),
+		restarts: make(map[string]int64),
+	}
+}
+
+// SetServerStates sets the function reporting which servers are connected,
+// called on every scrape
+func (m *Metrics) SetServerStates(states func() map[string]bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states = states
+}
+
+// ServerRestarted counts a restart of a server's process
+func (m *Metrics) ServerRestarted(serverName string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restarts[serverName]++
+}
+
+// series returns the series of a tool, creating it if needed. Callers must
+// hold m.mu.
+func (m *Metrics) series(serverName, toolName string) *toolSeries {
+	key := toolKey{server: serverName, tool: toolName}
+	series, exists := m.tools[key]
+	if !exists {
+		series = &toolSeries{
+			errors:  make(map[string]int64),
+			buckets: make([]int64, len(latencyBuckets)+1),
+		}
+		m.tools[key] = series
+	}
+	return series
+}
+
+// callKey is the context key of the call being instrumented
+type callKey struct{}
+
+type call struct {
+	errorKind string
+}
+
+// SetErrorKind records why the instrumented call in ctx failed. Without it a
+// failed call is counted as a protocol or tool error.
+func SetErrorKind(ctx context.Context, kind string) {
+	if c, ok := ctx.Value(callKey{}).(*call); ok {
+		c.errorKind = kind
+	}
+}
+
+// ClassifyError returns the error kind of a failed upstream call
+func ClassifyError(err error) string {
+	message := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(message, "timeout"):
+		return ErrorTimeout
+	case errors.Is(err, io.EOF) || strings.Contains(message, "not connected") ||
+		strings.Contains(message, "broken pipe") || strings.Contains(message, "closed") ||
+		strings.Contains(message, "failed to read response") || strings.Contains(message, "failed to write request"):
+		return ErrorConnection
+	default:
+		return ErrorProtocol
+	}
+}
+
+// Instrument wraps a tool handler to count its calls, errors, latency and
+// bytes. Returns the handler unchanged for nil metrics.
+func (m *Metrics) Instrument(serverName, toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if m == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		requestBytes := 0
+		if args, err := json.Marshal(request.GetArguments()); err == nil {
+			requestBytes = len(args)
+		}
+
+		m.mu.Lock()
+		series := m.series(serverName, toolName)
+		series.calls++
+		series.inFlight++
+		series.requestBytes += int64(requestBytes)
+		m.mu.Unlock()
+
+		c := &call{}
+		start := time.Now()
+		result, err := handler(context.WithValue(ctx, callKey{}, c), request)
+		elapsed := time.Since(start).Seconds()
+
+		errorKind := c.errorKind
+		responseBytes := 0
+		switch {
+		case err != nil:
+			if errorKind == "" {
+				errorKind = ErrorProtocol
+			}
+		case result != nil:
+			for _, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					responseBytes += len(text.Text)
+				}
+			}
+			if result.IsError && errorKind == "" {
+				errorKind = ErrorTool
+			}
+		}
+
+		m.mu.Lock()
+		series.inFlight--
+		series.responseBytes += int64(responseBytes)
+		if errorKind != "" {
+			series.errors[errorKind]++
+		}
+		bucket := sort.SearchFloat64s(latencyBuckets, elapsed)
+		series.buckets[bucket]++
+		series.latencySum += elapsed
+		m.mu.Unlock()
+
+		return result, err
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.Write(w)
+}
+
+// Listen serves the metrics on addr under /metrics. It returns once the
+// address is bound; the server runs until the process exits.
+func (m *Metrics) Listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	go http.Serve(listener, mux)
+	return nil
+}
+
+// Write writes the metrics in the Prometheus text format
+func (m *Metrics) Write(w io.Writer) {
+	m.mu.Lock()
+	states := m.states
+	m.mu.Unlock()
+
+	// Ask for server states without holding the lock, the callback takes its own
+	var serverStates map[string]bool
+	if states != nil {
+		serverStates = states()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]toolKey, 0, len(m.tools))
+	for key := range m.tools {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].server != keys[j].server {
+			return keys[i].server < keys[j].server
+		}
+		return keys[i].tool < keys[j].tool
+	})
+
+	header(w, "mcp_proxy_tool_calls_total", "counter", "Tool calls handled by the proxy.")
+	for _, key := range keys {
+		fmt.Fprintf(w, "mcp_proxy_tool_calls_total{%s} %d\n", toolLabels(key), m.tools[key].calls)
+	}
+
+	header(w, "mcp_proxy_tool_errors_total", "counter", "Failed tool calls by kind of error.")
+	for _, key := range keys {
+		series := m.tools[key]
+		kinds := make([]string, 0, len(series.errors))
+		for kind := range series.errors {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Fprintf(w, "mcp_proxy_tool_errors_total{%s,kind=%s} %d\n", toolLabels(key), quote(kind), series.errors[kind])
+		}
+	}
+
+	header(w, "mcp_proxy_tool_calls_in_flight", "gauge", "Tool calls currently being handled.")
+	for _, key := range keys {
+		fmt.Fprintf(w, "mcp_proxy_tool_calls_in_flight{%s} %d\n", toolLabels(key), m.tools[key].inFlight)
+	}
+
+	header(w, "mcp_proxy_tool_call_duration_seconds", "histogram", "Time to handle a tool call.")
+	for _, key := range keys {
+		series := m.tools[key]
+		labels := toolLabels(key)
+		var cumulative int64
+		for i, bound := range latencyBuckets {
+			cumulative += series.buckets[i]
+			fmt.Fprintf(w, "mcp_proxy_tool_call_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bound, cumulative)
+		}
+		cumulative += series.buckets[len(latencyBuckets)]
+		fmt.Fprintf(w, "mcp_proxy_tool_call_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, cumulative)
+		fmt.Fprintf(w, "mcp_proxy_tool_call_duration_seconds_sum{%s} %g\n", labels, series.latencySum)
+		fmt.Fprintf(w, "mcp_proxy_tool_call_duration_seconds_count{%s} %d\n", labels, cumulative)
+	}
+
+	header(w, "mcp_proxy_tool_bytes_total", "counter", "Bytes of tool arguments sent and result text returned.")
+	for _, key := range keys {
+		series := m.tools[key]
+		fmt.Fprintf(w, "mcp_proxy_tool_bytes_total{%s,direction=\"request\"} %d\n", toolLabels(key), series.requestBytes)
+		fmt.Fprintf(w, "mcp_proxy_tool_bytes_total{%s,direction=\"response\"} %d\n", toolLabels(key), series.responseBytes)
+	}
+
+	header(w, "mcp_proxy_server_restarts_total", "counter", "Server processes restarted with server_reconnect.")
+	for _, name := range sortedNames(m.restarts) {
+		fmt.Fprintf(w, "mcp_proxy_server_restarts_total{server=%s} %d\n", quote(name), m.restarts[name])
+	}
+
+	header(w, "mcp_proxy_server_connected", "gauge", "Whether the proxy is connected to a server (1) or not (0).")
+	for _, name := range sortedNames(serverStates) {
+		connected := 0
+		if serverStates[name] {
+			connected = 1
+		}
+		fmt.Fprintf(w, "mcp_proxy_server_connected{server=%s} %d\n", quote(name), connected)
+	}
+}
+
+func header(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func toolLabels(key toolKey) string {
+	return fmt.Sprintf("server=%s,tool=%s", quote(key.server), quote(key.tool))
+}
+
+// quote quotes a label value, escaping backslashes, quotes and newlines
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
+
+func sortedNames[V any](values map[string]V) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,7 +1,6 @@
 package playback
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -42,7 +41,7 @@ func (c *PlaybackClient) Run() error {
 	log.Printf("Starting playback client with %d messages", len(c.messages))
 	
 	// Wait for server to be ready by reading from stdin
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := newLineScanner(os.Stdin)
 	messageIndex := 0
 	
 	for scanner.Scan() {
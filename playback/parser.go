@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -11,6 +12,18 @@ import (
 	"mcp-debug/integration"
 )
 
+// maxLineSize bounds a single line of a recording or of a replayed stream.
+// bufio.Scanner stops at 64 KB by default, which a recorded message with a
+// large argument or result easily exceeds.
+const maxLineSize = 64 << 20
+
+// newLineScanner returns a line scanner that accepts lines up to maxLineSize
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return scanner
+}
+
 // PlaybackSession represents a parsed recording session
 type PlaybackSession struct {
 	StartTime  time.Time                        `json:"start_time"`
@@ -26,7 +39,7 @@ func ParseRecordingFile(filename string) (*PlaybackSession, error) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner := newLineScanner(file)
 	var session *PlaybackSession
 	var messages []integration.RecordedMessage
 
@@ -1,7 +1,6 @@
 package playback
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -41,7 +40,7 @@ func (s *PlaybackServer) SetDelay(delay time.Duration) {
 func (s *PlaybackServer) Run() error {
 	log.Printf("Starting playback server with %d responses", len(s.responses))
 	
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := newLineScanner(os.Stdin)
 	responseIndex := 0
 	
 	for scanner.Scan() {
@@ -89,7 +88,7 @@ func (s *PlaybackServer) Run() error {
 func (s *PlaybackServer) RunStateless() error {
 	log.Printf("Starting stateless playback server")
 	
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := newLineScanner(os.Stdin)
 	responseIndex := 0
 	
 	for scanner.Scan() {
@@ -0,0 +1,210 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+	"mcp-debug/metrics"
+)
+
+// codeInternalError is the JSON-RPC internal error code
+const codeInternalError = -32603
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // calls pass through
+	CircuitOpen                         // calls fail immediately
+	CircuitHalfOpen                     // one probe call at a time passes through
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops forwarding calls to a server that keeps failing, so
+// callers get an error at once instead of waiting for every call to time out.
+// Only timeouts, lost connections and internal errors count as failures;
+// tool results flagged as errors are successful calls. A nil breaker lets every call through.
+type CircuitBreaker struct {
+	failureThreshold int
+	successThreshold int
+	openTimeout      time.Duration
+
+	state     CircuitState
+	failures  int // consecutive failures while closed
+	successes int // consecutive successful probes while half-open
+	probing   bool
+	openedAt  time.Time
+	lastError string
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. A nil config uses the
+// defaults; returns nil if the breaker is disabled.
+func NewCircuitBreaker(cfg *config.CircuitBreakerConfig) *CircuitBreaker {
+	if cfg != nil && cfg.Disabled {
+		return nil
+	}
+	return &CircuitBreaker{
+		failureThreshold: cfg.GetFailureThreshold(),
+		successThreshold: cfg.GetSuccessThreshold(),
+		openTimeout:      cfg.GetOpenTimeout(),
+	}
+}
+
+// Allow returns an error if a call must not be forwarded, otherwise a
+// function that must be called with the call's error once it has finished.
+// While half-open the allowed call is the probe, and only its outcome moves
+// the circuit.
+func (b *CircuitBreaker) Allow() (func(error), error) {
+	if b == nil {
+		return func(error) {}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		remaining := b.openTimeout - time.Since(b.openedAt)
+		if remaining > 0 {
+			return nil, fmt.Errorf("circuit open after repeated failures (last error: %s), retrying in %v",
+				b.lastError, remaining.Round(time.Second))
+		}
+		b.state = CircuitHalfOpen
+		b.successes = 0
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probing {
+			return nil, fmt.Errorf("circuit half-open, waiting for a probe call to finish")
+		}
+		b.probing = true
+		return b.recordProbe, nil
+	}
+	return b.record, nil
+}
+
+// record reports the outcome of a call allowed while the circuit was closed.
+// Calls finishing after the circuit has opened no longer count.
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitClosed || ignored(err) {
+		return
+	}
+	if isFailure(err) {
+		b.lastError = err.Error()
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			b.trip()
+		}
+		return
+	}
+	b.failures = 0
+}
+
+// recordProbe reports the outcome of the probe call of a half-open circuit
+func (b *CircuitBreaker) recordProbe(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if ignored(err) {
+		return
+	}
+	if isFailure(err) {
+		b.lastError = err.Error()
+		b.trip()
+		return
+	}
+	b.successes++
+	if b.successes >= b.successThreshold {
+		b.state = CircuitClosed
+		b.failures = 0
+	}
+}
+
+// ignored reports whether an error says nothing about the server: the caller
+// cancelled the call, or chaos mode injected the error
+func ignored(err error) bool {
+	return errors.Is(err, context.Canceled) || IsFault(err)
+}
+
+// isFailure reports whether an error means the server is failing: the call
+// timed out, the connection is lost, or the server answered with an internal
+// error. Other JSON-RPC errors, such as invalid params, and malformed
+// responses come from a server that is still answering.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var clientErr *client.ClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.Code == codeInternalError
+	}
+	kind := metrics.ClassifyError(err)
+	return kind == metrics.ErrorTimeout || kind == metrics.ErrorConnection
+}
+
+// trip opens the circuit
+func (b *CircuitBreaker) trip() {
+	b.state = CircuitOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+}
+
+// State returns the current state. An open circuit whose timeout has passed
+// is reported as half-open, since the next call will probe.
+func (b *CircuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.openTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Status describes the state for display
+func (b *CircuitBreaker) Status() string {
+	if b == nil {
+		return "disabled"
+	}
+
+	state := b.State()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch state {
+	case CircuitOpen:
+		return fmt.Sprintf("open, retrying in %v (last error: %s)",
+			(b.openTimeout - time.Since(b.openedAt)).Round(time.Second), b.lastError)
+	case CircuitHalfOpen:
+		return fmt.Sprintf("half-open (last error: %s)", b.lastError)
+	default:
+		if b.failures > 0 {
+			return fmt.Sprintf("closed (%d of %d failures)", b.failures, b.failureThreshold)
+		}
+		return "closed"
+	}
+}
@@ -0,0 +1,188 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+)
+
+func TestIsFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"success", nil, false},
+		{"timeout", fmt.Errorf("request timeout: %w", context.DeadlineExceeded), true},
+		{"lost connection", fmt.Errorf("failed to read response: %w", io.EOF), true},
+		{"not connected", errors.New("client not connected"), true},
+		{"internal error", client.NewClientError("s", codeInternalError, "boom"), true},
+		{"invalid params", client.NewClientError("s", -32602, "bad arguments"), false},
+		{"malformed response", errors.New("failed to parse result"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFailure(tt.err); got != tt.want {
+				t.Errorf("isFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"cancelled", fmt.Errorf("call: %w", context.Canceled), true},
+		{"injected fault", &FaultError{Kind: FaultDisconnect, err: io.EOF}, true},
+		{"real failure", io.EOF, false},
+		{"success", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ignored(tt.err); got != tt.want {
+				t.Errorf("ignored(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// callThrough runs one call through the breaker, returning Allow's error
+func callThrough(b *CircuitBreaker, callErr error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	done(callErr)
+	return nil
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	failure := fmt.Errorf("failed to read response: %w", io.EOF)
+	b := NewCircuitBreaker(&config.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+		OpenTimeout:      "30ms",
+	})
+
+	steps := []struct {
+		name    string
+		callErr error
+		state   CircuitState
+	}{
+		{"first failure", failure, CircuitClosed},
+		{"success resets the count", nil, CircuitClosed},
+		{"failure", failure, CircuitClosed},
+		{"ignored error", context.Canceled, CircuitClosed},
+		{"tool error result", client.NewClientError("s", -32602, "bad"), CircuitClosed},
+		{"failure", failure, CircuitClosed},
+		{"threshold reached", failure, CircuitOpen},
+	}
+	for _, step := range steps {
+		if err := callThrough(b, step.callErr); err != nil {
+			t.Fatalf("%s: Allow() error = %v", step.name, err)
+		}
+		if got := b.State(); got != step.state {
+			t.Fatalf("%s: State() = %v, want %v", step.name, got, step.state)
+		}
+	}
+
+	if err := callThrough(b, nil); err == nil || !strings.Contains(err.Error(), "circuit open") {
+		t.Fatalf("Allow() while open error = %v, want circuit open", err)
+	}
+
+	// A failed probe opens the circuit again
+	time.Sleep(40 * time.Millisecond)
+	if got := b.State(); got != CircuitHalfOpen {
+		t.Fatalf("State() after open timeout = %v, want half-open", got)
+	}
+	if err := callThrough(b, failure); err != nil {
+		t.Fatalf("probe Allow() error = %v", err)
+	}
+	if got := b.State(); got != CircuitOpen {
+		t.Fatalf("State() after failed probe = %v, want open", got)
+	}
+
+	// Successful probes close it
+	time.Sleep(40 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := callThrough(b, nil); err != nil {
+			t.Fatalf("probe %d Allow() error = %v", i+1, err)
+		}
+	}
+	if got := b.State(); got != CircuitClosed {
+		t.Fatalf("State() after successful probes = %v, want closed", got)
+	}
+}
+
+func TestCircuitBreakerOneProbeAtATime(t *testing.T) {
+	b := NewCircuitBreaker(&config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: "10ms"})
+	if err := callThrough(b, io.EOF); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	probeDone, err := b.Allow()
+	if err != nil {
+		t.Fatalf("probe Allow() error = %v", err)
+	}
+	if _, err := b.Allow(); err == nil || !strings.Contains(err.Error(), "waiting for a probe") {
+		t.Fatalf("second Allow() error = %v, want waiting for a probe", err)
+	}
+
+	// An ignored probe outcome frees the probe slot without moving the circuit
+	probeDone(context.Canceled)
+	if got := b.State(); got != CircuitHalfOpen {
+		t.Fatalf("State() after ignored probe = %v, want half-open", got)
+	}
+	if err := callThrough(b, nil); err != nil {
+		t.Fatalf("next probe Allow() error = %v", err)
+	}
+}
+
+func TestCircuitBreakerLateCallsIgnored(t *testing.T) {
+	b := NewCircuitBreaker(&config.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: "10ms"})
+
+	slowDone, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if err := callThrough(b, io.EOF); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// A call allowed while closed finishing after the circuit opened must
+	// not close it
+	slowDone(nil)
+	if got := b.State(); got != CircuitHalfOpen {
+		t.Fatalf("State() = %v, want half-open", got)
+	}
+}
+
+func TestNilCircuitBreaker(t *testing.T) {
+	b := NewCircuitBreaker(&config.CircuitBreakerConfig{Disabled: true})
+	if b != nil {
+		t.Fatalf("NewCircuitBreaker(disabled) = %+v, want nil", b)
+	}
+	for i := 0; i < 10; i++ {
+		if err := callThrough(b, io.EOF); err != nil {
+			t.Fatalf("nil Allow() error = %v", err)
+		}
+	}
+	if got := b.State(); got != CircuitClosed {
+		t.Errorf("nil State() = %v, want closed", got)
+	}
+	if got := b.Status(); got != "disabled" {
+		t.Errorf("nil Status() = %q, want disabled", got)
+	}
+}
@@ -0,0 +1,170 @@
+package proxy
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+	"mcp-debug/discovery"
+)
+
+// ResponseCache caches tool results keyed by server, tool and arguments. It
+// holds at most a configured number of entries and bytes of result text,
+// evicting the least recently used entries first.
+type ResponseCache struct {
+	maxEntries int
+	maxBytes   int
+
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+	bytes   int
+	hits    int
+	misses  int
+	mu      sync.Mutex
+}
+
+type cacheEntry struct {
+	key        string
+	serverName string
+	result     *client.CallToolResult
+	expires    time.Time
+	size       int
+}
+
+// NewResponseCache creates an empty cache with the configured size limits
+func NewResponseCache(cfg config.CacheConfig) *ResponseCache {
+	return &ResponseCache{
+		maxEntries: cfg.GetMaxEntries(),
+		maxBytes:   cfg.GetMaxBytes(),
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// CacheKey returns the cache key of a call. Arguments are canonicalized by
+// encoding them as JSON, which orders object keys.
+func CacheKey(serverName, toolName string, args map[string]interface{}) string {
+	argsBytes, _ := json.Marshal(args)
+	return serverName + "\x00" + toolName + "\x00" + string(argsBytes)
+}
+
+// Lookup returns the cache key of a call to a tool along with the cached
+// result, if there is one. The key is empty if the tool's results are not
+// cached or the cache is nil.
+func (c *ResponseCache) Lookup(tool discovery.RemoteTool, args map[string]interface{}) (string, *client.CallToolResult, bool) {
+	if c == nil || tool.CacheTTL <= 0 {
+		return "", nil, false
+	}
+	key := CacheKey(tool.ServerName, tool.OriginalName, args)
+	result, hit := c.Get(key)
+	return key, result, hit
+}
+
+// Store caches the result of a call looked up with Lookup
+func (c *ResponseCache) Store(key string, tool discovery.RemoteTool, result *client.CallToolResult) {
+	if c == nil || key == "" {
+		return
+	}
+	c.Put(key, tool.ServerName, result, tool.CacheTTL)
+}
+
+// Get returns the cached result for a key, if there is one that has not expired
+func (c *ResponseCache) Get(key string) (*client.CallToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		c.misses++
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		c.misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	c.hits++
+	return entry.result, true
+}
+
+// Put caches a result for ttl. Error results and results larger than the
+// cache itself are not cached.
+func (c *ResponseCache) Put(key, serverName string, result *client.CallToolResult, ttl time.Duration) {
+	if result == nil || result.IsError || ttl <= 0 {
+		return
+	}
+
+	size := 0
+	for _, content := range result.Content {
+		size += len(content.Text)
+	}
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		c.remove(element)
+	}
+
+	entry := &cacheEntry{
+		key:        key,
+		serverName: serverName,
+		result:     result,
+		expires:    time.Now().Add(ttl),
+		size:       size,
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += size
+
+	for len(c.entries) > c.maxEntries || c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// Clear removes the cached results of a server, or of all servers if
+// serverName is empty. Returns the number of results removed.
+func (c *ResponseCache) Clear(serverName string) int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for _, element := range c.entries {
+		if serverName == "" || element.Value.(*cacheEntry).serverName == serverName {
+			c.remove(element)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Stats returns the number of cached results and the hit and miss counts
+func (c *ResponseCache) Stats() (entries, hits, misses int) {
+	if c == nil {
+		return 0, 0, 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries), c.hits, c.misses
+}
+
+func (c *ResponseCache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
@@ -0,0 +1,165 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+	"mcp-debug/discovery"
+)
+
+func textResult(text string) *client.CallToolResult {
+	return &client.CallToolResult{Content: []client.ContentItem{{Type: "text", Text: text}}}
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  map[string]interface{}
+		equal bool
+	}{
+		{"key order", map[string]interface{}{"a": 1, "b": 2}, map[string]interface{}{"b": 2, "a": 1}, true},
+		{"nil and empty", nil, map[string]interface{}{}, false},
+		{"different values", map[string]interface{}{"a": 1}, map[string]interface{}{"a": "1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CacheKey("s", "t", tt.a) == CacheKey("s", "t", tt.b); got != tt.equal {
+				t.Errorf("keys equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+	if CacheKey("s", "t", nil) == CacheKey("s2", "t", nil) {
+		t.Errorf("keys of different servers are equal")
+	}
+}
+
+func TestResponseCachePut(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *client.CallToolResult
+		ttl    time.Duration
+		cached bool
+	}{
+		{"result", textResult("ok"), time.Minute, true},
+		{"error result", &client.CallToolResult{Content: []client.ContentItem{{Text: "failed"}}, IsError: true}, time.Minute, false},
+		{"nil result", nil, time.Minute, false},
+		{"no ttl", textResult("ok"), 0, false},
+		{"larger than the cache", textResult(strings.Repeat("x", 11)), time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewResponseCache(config.CacheConfig{MaxBytes: 10})
+			c.Put("k", "s", tt.result, tt.ttl)
+			if _, hit := c.Get("k"); hit != tt.cached {
+				t.Errorf("Get() hit = %v, want %v", hit, tt.cached)
+			}
+		})
+	}
+}
+
+func TestResponseCacheExpires(t *testing.T) {
+	c := NewResponseCache(config.CacheConfig{})
+	c.Put("k", "s", textResult("ok"), 20*time.Millisecond)
+	if _, hit := c.Get("k"); !hit {
+		t.Fatalf("Get() before expiry missed")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, hit := c.Get("k"); hit {
+		t.Fatalf("Get() after expiry hit")
+	}
+	if entries, hits, misses := c.Stats(); entries != 0 || hits != 1 || misses != 1 {
+		t.Errorf("Stats() = %d, %d, %d, want 0 entries, 1 hit, 1 miss", entries, hits, misses)
+	}
+}
+
+func TestResponseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.CacheConfig
+		texts   []string
+		touch   string
+		evicted string
+	}{
+		{"max entries", config.CacheConfig{MaxEntries: 2}, []string{"a", "b", "c"}, "", "a"},
+		{"max entries after use", config.CacheConfig{MaxEntries: 2}, []string{"a", "b", "c"}, "a", "b"},
+		{"max bytes", config.CacheConfig{MaxBytes: 8}, []string{"aaaa", "bbbb", "cccc"}, "", "aaaa"},
+		{"max bytes after use", config.CacheConfig{MaxBytes: 8}, []string{"aaaa", "bbbb", "cccc"}, "aaaa", "bbbb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewResponseCache(tt.cfg)
+			for i, text := range tt.texts {
+				if i == len(tt.texts)-1 && tt.touch != "" {
+					c.Get(tt.touch)
+				}
+				c.Put(text, "s", textResult(text), time.Minute)
+			}
+			for _, text := range tt.texts {
+				if _, hit := c.Get(text); hit == (text == tt.evicted) {
+					t.Errorf("Get(%q) hit = %v, want evicted %q", text, hit, tt.evicted)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseCacheReplace(t *testing.T) {
+	c := NewResponseCache(config.CacheConfig{MaxBytes: 10})
+	c.Put("k", "s", textResult("first"), time.Minute)
+	c.Put("k", "s", textResult("second"), time.Minute)
+	result, hit := c.Get("k")
+	if !hit || result.Content[0].Text != "second" {
+		t.Fatalf("Get() = %+v, %v, want the second result", result, hit)
+	}
+
+	// The replaced entry's size no longer counts
+	c.Put("k2", "s", textResult("four"), time.Minute)
+	if _, hit := c.Get("k"); !hit {
+		t.Errorf("Get() missed, replaced entry still counted against the size limit")
+	}
+}
+
+func TestResponseCacheClear(t *testing.T) {
+	c := NewResponseCache(config.CacheConfig{})
+	c.Put("a1", "a", textResult("1"), time.Minute)
+	c.Put("a2", "a", textResult("2"), time.Minute)
+	c.Put("b1", "b", textResult("3"), time.Minute)
+
+	if removed := c.Clear("a"); removed != 2 {
+		t.Errorf("Clear(a) = %d, want 2", removed)
+	}
+	if _, hit := c.Get("b1"); !hit {
+		t.Errorf("Clear(a) removed another server's result")
+	}
+	if removed := c.Clear(""); removed != 1 {
+		t.Errorf("Clear() = %d, want 1", removed)
+	}
+}
+
+func TestResponseCacheLookup(t *testing.T) {
+	args := map[string]interface{}{"q": "x"}
+	uncached := discovery.RemoteTool{ServerName: "s", OriginalName: "t"}
+	cached := discovery.RemoteTool{ServerName: "s", OriginalName: "t", CacheTTL: time.Minute}
+
+	var nilCache *ResponseCache
+	if key, _, hit := nilCache.Lookup(cached, args); key != "" || hit {
+		t.Errorf("nil Lookup() = %q, %v, want no key", key, hit)
+	}
+
+	c := NewResponseCache(config.CacheConfig{})
+	if key, _, _ := c.Lookup(uncached, args); key != "" {
+		t.Errorf("Lookup() of an uncached tool = %q, want no key", key)
+	}
+
+	key, _, hit := c.Lookup(cached, args)
+	if key == "" || hit {
+		t.Fatalf("first Lookup() = %q, %v, want a key and a miss", key, hit)
+	}
+	c.Store(key, cached, textResult("ok"))
+	if _, result, hit := c.Lookup(cached, args); !hit || result.Content[0].Text != "ok" {
+		t.Errorf("Lookup() after Store = %+v, %v, want the stored result", result, hit)
+	}
+}
@@ -0,0 +1,269 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+	"mcp-debug/logging"
+	"mcp-debug/tracing"
+)
+
+// FaultKind is a kind of injected fault
+type FaultKind string
+
+const (
+	FaultToolError  FaultKind = "toolError"
+	FaultRPCError   FaultKind = "rpcError"
+	FaultDisconnect FaultKind = "disconnect"
+	FaultDrop       FaultKind = "drop"
+	FaultTruncate   FaultKind = "truncate"
+)
+
+// FaultError is the error of an injected fault. Disconnects and dropped
+// responses read like the errors of a real lost connection or timeout.
+type FaultError struct {
+	Kind FaultKind
+	err  error
+}
+
+func (e *FaultError) Error() string {
+	return e.err.Error()
+}
+
+func (e *FaultError) Unwrap() error {
+	return e.err
+}
+
+// IsFault reports whether an error was injected rather than real
+func IsFault(err error) bool {
+	var fault *FaultError
+	return errors.As(err, &fault)
+}
+
+// IsRPCFault reports whether an error is an injected JSON-RPC error, which
+// handlers return as the error of the request instead of a tool result
+func IsRPCFault(err error) bool {
+	var fault *FaultError
+	return errors.As(err, &fault) && fault.Kind == FaultRPCError
+}
+
+// Chaos holds a server's fault injection rules, which can be changed while
+// the proxy runs. A nil Chaos injects nothing.
+type Chaos struct {
+	rules  map[string]config.ChaosRules // tool name or "*" -> rules
+	random *rand.Rand
+	mu     sync.Mutex
+}
+
+// NewChaos creates a server's fault injection with the configured rules
+func NewChaos(rules map[string]config.ChaosRules) *Chaos {
+	c := &Chaos{
+		rules:  make(map[string]config.ChaosRules, len(rules)),
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for toolName, r := range rules {
+		c.Set(toolName, r)
+	}
+	return c
+}
+
+// Set replaces the rules for a tool name or "*". Empty rules remove them.
+func (c *Chaos) Set(toolName string, rules config.ChaosRules) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rules == (config.ChaosRules{}) {
+		delete(c.rules, toolName)
+		return
+	}
+	c.rules[toolName] = rules
+}
+
+// rulesFor returns the rules for a tool; a tool's own rules replace those
+// for "*"
+func (c *Chaos) rulesFor(toolName string) (config.ChaosRules, bool) {
+	if rules, exists := c.rules[toolName]; exists {
+		return rules, true
+	}
+	rules, exists := c.rules["*"]
+	return rules, exists
+}
+
+// Inject waits out the added latency for a call and picks its fault, nil if
+// the call goes through unchanged
+func (c *Chaos) Inject(ctx context.Context, toolName string) (*Fault, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	rules, exists := c.rulesFor(toolName)
+	var delay time.Duration
+	var roll float64
+	if exists {
+		delay = rules.GetLatency()
+		if jitter := rules.GetJitter(); jitter > 0 {
+			delay += time.Duration(c.random.Int63n(int64(jitter) + 1))
+		}
+		roll = c.random.Float64()
+	}
+	c.mu.Unlock()
+	if !exists {
+		return nil, nil
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+
+	for _, candidate := range faultProbabilities(rules) {
+		if roll < candidate.probability {
+			logging.For(logging.ComponentChaos).InfoContext(ctx, "Injecting fault", "fault", string(candidate.kind))
+			tracing.FromContext(ctx).SetAttribute("mcp.chaos.fault", string(candidate.kind))
+			return &Fault{Kind: candidate.kind, truncateLength: rules.TruncateLength, dropTimeout: rules.GetDropTimeout()}, nil
+		}
+		roll -= candidate.probability
+	}
+	return nil, nil
+}
+
+type faultProbability struct {
+	kind        FaultKind
+	probability float64
+}
+
+// faultProbabilities lists the faults of a rule set in the order they are rolled
+func faultProbabilities(rules config.ChaosRules) []faultProbability {
+	return []faultProbability{
+		{FaultToolError, rules.ToolError},
+		{FaultRPCError, rules.RPCError},
+		{FaultDisconnect, rules.Disconnect},
+		{FaultDrop, rules.Drop},
+		{FaultTruncate, rules.Truncate},
+	}
+}
+
+// Status describes the rules for display, empty if there are none
+func (c *Chaos) Status() string {
+	if c == nil {
+		return ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var toolNames []string
+	for toolName := range c.rules {
+		toolNames = append(toolNames, toolName)
+	}
+	sort.Strings(toolNames)
+
+	var parts []string
+	for _, toolName := range toolNames {
+		parts = append(parts, fmt.Sprintf("%s: %s", toolName, describeRules(c.rules[toolName])))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeRules lists the latency and the faults with their probabilities
+func describeRules(rules config.ChaosRules) string {
+	var parts []string
+	if rules.Latency != "" || rules.Jitter != "" {
+		parts = append(parts, fmt.Sprintf("latency %v+%v", rules.GetLatency(), rules.GetJitter()))
+	}
+	for _, fault := range faultProbabilities(rules) {
+		if fault.probability > 0 {
+			parts = append(parts, fmt.Sprintf("%s %g%%", fault.kind, fault.probability*100))
+		}
+	}
+	if rules.Drop > 0 && rules.DropTimeout != "" {
+		parts = append(parts, fmt.Sprintf("drop timeout %v", rules.GetDropTimeout()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Fault is the fault picked for a call. A nil Fault changes nothing.
+type Fault struct {
+	Kind           FaultKind
+	truncateLength int
+	dropTimeout    time.Duration // 0 waits until the call's context ends
+}
+
+// Call makes the upstream call, unless the fault replaces it with an error.
+// Replaced calls never reach the server, so a forced error cannot run a tool
+// with side effects.
+func (f *Fault) Call(ctx context.Context, call func() (*client.CallToolResult, error)) (*client.CallToolResult, error) {
+	if f == nil {
+		return call()
+	}
+
+	switch f.Kind {
+	case FaultToolError:
+		return &client.CallToolResult{
+			Content: []client.ContentItem{{Type: "text", Text: "injected tool error"}},
+			IsError: true,
+		}, nil
+	case FaultRPCError:
+		return nil, &FaultError{Kind: f.Kind, err: fmt.Errorf("injected internal error")}
+	case FaultDisconnect:
+		return nil, &FaultError{Kind: f.Kind, err: fmt.Errorf("tools/call request failed: failed to read response: %w", io.EOF)}
+	case FaultDrop:
+		// The server handles the call, but its response never arrives. The
+		// call hangs until its context ends or the drop timeout passes.
+		call()
+		var deadline <-chan time.Time
+		if f.dropTimeout > 0 {
+			timer := time.NewTimer(f.dropTimeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		select {
+		case <-deadline:
+		case <-ctx.Done():
+		}
+		return nil, &FaultError{Kind: f.Kind, err: fmt.Errorf("tools/call request failed: request timeout: %w", context.DeadlineExceeded)}
+	}
+	return call()
+}
+
+// Apply alters a successful result, returning a copy
+func (f *Fault) Apply(result *client.CallToolResult) *client.CallToolResult {
+	if f == nil || result.IsError {
+		return result
+	}
+
+	switch f.Kind {
+	case FaultTruncate:
+		var texts []string
+		for _, content := range result.Content {
+			texts = append(texts, content.Text)
+		}
+		runes := []rune(strings.Join(texts, "\n"))
+		length := f.truncateLength
+		if length == 0 {
+			length = len(runes) / 2
+		}
+		if length >= len(runes) {
+			return result
+		}
+		return &client.CallToolResult{
+			Content: []client.ContentItem{{Type: "text", Text: string(runes[:length])}},
+		}
+	}
+	return result
+}
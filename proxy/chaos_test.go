@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mcp-debug/client"
+)
+
+func TestDropFault(t *testing.T) {
+	tests := []struct {
+		name        string
+		dropTimeout time.Duration
+		ctxTimeout  time.Duration
+	}{
+		{"drop timeout", 20 * time.Millisecond, time.Minute},
+		{"context ends first", time.Minute, 20 * time.Millisecond},
+		{"no drop timeout", 0, 20 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.ctxTimeout)
+			defer cancel()
+
+			called := false
+			fault := &Fault{Kind: FaultDrop, dropTimeout: tt.dropTimeout}
+			start := time.Now()
+			_, err := fault.Call(ctx, func() (*client.CallToolResult, error) {
+				called = true
+				return &client.CallToolResult{}, nil
+			})
+
+			if !called {
+				t.Errorf("dropped call did not reach the server")
+			}
+			if !IsFault(err) || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Call() error = %v, want injected timeout", err)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("Call() took %v", elapsed)
+			}
+		})
+	}
+}
@@ -3,33 +3,139 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"sync"
 	
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	
 	"mcp-debug/client"
+	"mcp-debug/dashboard"
 	"mcp-debug/discovery"
+	"mcp-debug/logging"
+	"mcp-debug/metrics"
+	"mcp-debug/tracing"
 )
 
+// HandlerOptions are the facilities a proxy handler uses besides the client.
+// Any of them may be nil.
+type HandlerOptions struct {
+	Limiter     *Limiter             // the server's concurrency and rate limits
+	Breaker     *CircuitBreaker      // the server's circuit breaker
+	Cache       *ResponseCache       // serves results of tools with a cache TTL
+	Chaos       *Chaos               // the server's fault injection
+	Metrics     *metrics.Metrics     // counts calls, errors, latency and bytes
+	Tracer      *tracing.Tracer      // records spans of the call
+	Dashboard   *dashboard.Dashboard // shows the call in the live stream
+	OnViolation ViolationReporter    // receives schema violations in warn mode
+	OnCallError func(err error)      // receives errors of forwarded calls, e.g. to notice a lost connection
+	OnCacheHit  func()               // called when the cache answers a call
+}
+
 // CreateProxyHandler creates a handler that forwards tool calls to remote servers
-func CreateProxyHandler(mcpClient client.MCPClient, remoteTool discovery.RemoteTool) server.ToolHandlerFunc {
+func CreateProxyHandler(mcpClient client.MCPClient, remoteTool discovery.RemoteTool, opts HandlerOptions) server.ToolHandlerFunc {
+	handler := ForwardHandler(mcpClient, remoteTool, opts)
+	handler = opts.Metrics.Instrument(remoteTool.ServerName, remoteTool.PrefixedName, handler)
+	handler = opts.Tracer.Instrument(remoteTool.ServerName, remoteTool.PrefixedName, handler)
+	handler = opts.Dashboard.Instrument(remoteTool.ServerName, remoteTool.PrefixedName, handler)
+	return logging.Instrument(remoteTool.ServerName, remoteTool.PrefixedName, handler)
+}
+
+// ForwardHandler creates the handler CreateProxyHandler instruments: argument
+// rules and validation, cache, limits, circuit breaker, chaos and the
+// response transform. It is for callers that wrap it in a handler
+// instrumented on their own, and ignores the instrumentation options.
+func ForwardHandler(mcpClient client.MCPClient, remoteTool discovery.RemoteTool, opts HandlerOptions) server.ToolHandlerFunc {
+	// Rules were validated with the config, so a compile error is unexpected
+	transformer, err := NewResponseTransformer(remoteTool.TransformRules)
+	if err != nil {
+		logging.For(logging.ComponentServer).Warn("Ignoring response transform",
+			"server", remoteTool.ServerName, "tool", remoteTool.PrefixedName, "error", err)
+	}
+	
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Time spent in the proxy before the call is forwarded
+		_, prepare := tracing.Start(ctx, "proxy.prepare", tracing.SpanKindInternal)
+		defer prepare.End()
+		
 		// Extract arguments from the request
 		args, err := extractArguments(request)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to extract arguments: %v", err)), nil
 		}
 		
+		// Check the arguments against the schema the client was given
+		if rejected := CheckArguments(remoteTool, args, opts.OnViolation); rejected != nil {
+			metrics.SetErrorKind(ctx, metrics.ErrorInvalidArguments)
+			return rejected, nil
+		}
+		
+		// Serve repeated calls from the cache, keyed by the arguments to forward
+		args = remoteTool.ArgumentRules.Apply(args)
+		cacheKey, cached, hit := opts.Cache.Lookup(remoteTool, args)
+		if hit {
+			tracing.FromContext(ctx).SetAttribute("mcp.cache_hit", true)
+			if opts.OnCacheHit != nil {
+				opts.OnCacheHit()
+			}
+			return transformResult(cached, transformer), nil
+		}
+		
+		// Wait for the server's concurrency and rate limits
+		release, err := opts.Limiter.Acquire(ctx)
+		if err != nil {
+			metrics.SetErrorKind(ctx, metrics.ErrorLimited)
+			return mcp.NewToolResultError(fmt.Sprintf("[%s] %v", remoteTool.ServerName, err)), nil
+		}
+		defer release()
+		
+		// Fail fast while the server is failing
+		record, err := opts.Breaker.Allow()
+		if err != nil {
+			metrics.SetErrorKind(ctx, metrics.ErrorCircuitOpen)
+			return mcp.NewToolResultError(fmt.Sprintf("[%s] %v", remoteTool.ServerName, err)), nil
+		}
+		
+		prepare.End()
+		callCtx, span := tracing.StartCall(ctx, remoteTool.ServerName, remoteTool.OriginalName, args)
+		
+		// Add the configured latency and pick an injected fault, if any
+		fault, err := opts.Chaos.Inject(callCtx, remoteTool.OriginalName)
+		if err != nil {
+			span.EndCall(nil, err)
+			record(err)
+			metrics.SetErrorKind(ctx, metrics.ClassifyError(err))
+			return mcp.NewToolResultError(fmt.Sprintf("[%s] %v", remoteTool.ServerName, err)), nil
+		}
+		
 		// Forward the call to the remote server using the original tool name
-		result, err := mcpClient.CallTool(ctx, remoteTool.OriginalName, args)
+		result, err := fault.Call(callCtx, func() (*client.CallToolResult, error) {
+			return mcpClient.CallTool(callCtx, remoteTool.OriginalName, args)
+		})
+		span.EndCall(result, err)
+		record(err)
 		if err != nil {
+			metrics.SetErrorKind(ctx, metrics.ClassifyError(err))
+			if opts.OnCallError != nil {
+				opts.OnCallError(err)
+			}
+			if IsRPCFault(err) {
+				return nil, err
+			}
+			
 			// Wrap error with server context
 			errorMsg := fmt.Sprintf("[%s] %v", remoteTool.ServerName, err)
 			return mcp.NewToolResultError(errorMsg), nil
 		}
 		
+		// Check structured output against the tool's output schema
+		if rejected := CheckResult(remoteTool, result, opts.OnViolation); rejected != nil {
+			metrics.SetErrorKind(ctx, metrics.ErrorInvalidResult)
+			return rejected, nil
+		}
+		opts.Cache.Store(cacheKey, remoteTool, result)
+		
 		// Transform the result back to MCP format
-		mcpResult := transformResult(result)
+		mcpResult := transformResult(fault.Apply(result), transformer)
 		return mcpResult, nil
 	}
 }
@@ -49,12 +155,13 @@ func extractArguments(request mcp.CallToolRequest) (map[string]interface{}, erro
 	return result, nil
 }
 
-// transformResult transforms a client.CallToolResult to mcp.CallToolResult
-func transformResult(clientResult *client.CallToolResult) *mcp.CallToolResult {
+// transformResult transforms a client.CallToolResult to mcp.CallToolResult,
+// shaping the text with the tool's response transformer
+func transformResult(clientResult *client.CallToolResult, transformer *ResponseTransformer) *mcp.CallToolResult {
 	if clientResult.IsError {
 		// If the client result indicates an error, create an error result
 		if len(clientResult.Content) > 0 {
-			return mcp.NewToolResultError(clientResult.Content[0].Text)
+			return mcp.NewToolResultError(transformer.Apply(clientResult.Content[0].Text))
 		}
 		return mcp.NewToolResultError("Tool execution failed")
 	}
@@ -69,7 +176,7 @@ func transformResult(clientResult *client.CallToolResult) *mcp.CallToolResult {
 			}
 			text += content.Text
 		}
-		return mcp.NewToolResultText(text)
+		return mcp.NewToolResultText(transformer.Apply(text))
 	}
 	
 	return mcp.NewToolResultText("Tool executed successfully")
@@ -77,38 +184,231 @@ func transformResult(clientResult *client.CallToolResult) *mcp.CallToolResult {
 
 // ToolRegistry manages the mapping of tools to their handlers and clients
 type ToolRegistry struct {
-	tools   map[string]discovery.RemoteTool
-	clients map[string]client.MCPClient
+	tools    map[string]discovery.RemoteTool
+	handlers map[string]server.ToolHandlerFunc // prefixed name -> handler the tool is served with
+	clients  map[string]client.MCPClient
+	limiters map[string]*Limiter        // server name -> call limits
+	breakers map[string]*CircuitBreaker // server name -> circuit breaker
+	chaos    map[string]*Chaos          // server name -> fault injection
+	reserved map[string]bool            // names of tools the proxy serves itself
+	cache    *ResponseCache             // shared by all servers, nil if disabled
+	
+	// mu guards the maps, which are read by running calls while servers are
+	// added and removed
+	mu       sync.RWMutex
 }
 
 // NewToolRegistry creates a new tool registry
 func NewToolRegistry() *ToolRegistry {
 	return &ToolRegistry{
-		tools:   make(map[string]discovery.RemoteTool),
-		clients: make(map[string]client.MCPClient),
+		tools:    make(map[string]discovery.RemoteTool),
+		handlers: make(map[string]server.ToolHandlerFunc),
+		clients:  make(map[string]client.MCPClient),
+		limiters: make(map[string]*Limiter),
+		breakers: make(map[string]*CircuitBreaker),
+		chaos:    make(map[string]*Chaos),
+		reserved: make(map[string]bool),
 	}
 }
 
+// ReserveName marks a tool name as taken by the proxy itself
+func (r *ToolRegistry) ReserveName(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.reserved[name] = true
+}
+
+// IsReserved reports whether a tool name is taken by the proxy itself
+func (r *ToolRegistry) IsReserved(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return r.reserved[name]
+}
+
+// CheckConflicts returns an error if any of a server's tools would be exposed
+// under a reserved name, a name owned by another server, or a name used twice
+// by the server itself. The server's currently registered tools are ignored
+// since they are about to be replaced.
+func (r *ToolRegistry) CheckConflicts(serverName string, tools []discovery.RemoteTool) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	seen := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		if r.reserved[tool.PrefixedName] {
+			return fmt.Errorf("tool '%s' from server '%s' collides with built-in tool '%s'",
+				tool.OriginalName, serverName, tool.PrefixedName)
+		}
+		if existing, exists := r.tools[tool.PrefixedName]; exists && existing.ServerName != serverName {
+			return fmt.Errorf("tool '%s' from server '%s' and tool '%s' from server '%s' are both exposed as '%s'",
+				tool.OriginalName, serverName, existing.OriginalName, existing.ServerName, tool.PrefixedName)
+		}
+		if original, exists := seen[tool.PrefixedName]; exists {
+			return fmt.Errorf("tools '%s' and '%s' from server '%s' are both exposed as '%s'",
+				original, tool.OriginalName, serverName, tool.PrefixedName)
+		}
+		seen[tool.PrefixedName] = tool.OriginalName
+	}
+	return nil
+}
+
 // RegisterTool registers a tool with its associated client
 func (r *ToolRegistry) RegisterTool(tool discovery.RemoteTool, mcpClient client.MCPClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
 	r.tools[tool.PrefixedName] = tool
 	r.clients[tool.ServerName] = mcpClient
 }
 
+// SetHandler records the handler a tool is served with, so other tools can
+// call it the way a client would
+func (r *ToolRegistry) SetHandler(prefixedName string, handler server.ToolHandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.handlers[prefixedName] = handler
+}
+
+// GetHandler returns the handler a tool is served with
+func (r *ToolRegistry) GetHandler(prefixedName string) (server.ToolHandlerFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	handler, exists := r.handlers[prefixedName]
+	return handler, exists
+}
+
+// UnregisterTool removes a tool from the registry
+func (r *ToolRegistry) UnregisterTool(prefixedName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	delete(r.tools, prefixedName)
+	delete(r.handlers, prefixedName)
+}
+
+// UnregisterServer removes all tools and the client associated with a server
+func (r *ToolRegistry) UnregisterServer(serverName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	for name, tool := range r.tools {
+		if tool.ServerName == serverName {
+			delete(r.tools, name)
+			delete(r.handlers, name)
+		}
+	}
+	delete(r.clients, serverName)
+	delete(r.limiters, serverName)
+	delete(r.breakers, serverName)
+	delete(r.chaos, serverName)
+	r.cache.Clear(serverName)
+}
+
 // GetTool returns the tool metadata for a prefixed tool name
 func (r *ToolRegistry) GetTool(prefixedName string) (discovery.RemoteTool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
 	tool, exists := r.tools[prefixedName]
 	return tool, exists
 }
 
 // GetClient returns the MCP client for a server name
 func (r *ToolRegistry) GetClient(serverName string) (client.MCPClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
 	client, exists := r.clients[serverName]
 	return client, exists
 }
 
+// SetLimiter sets the limiter shared by a server's tools
+func (r *ToolRegistry) SetLimiter(serverName string, limiter *Limiter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.limiters[serverName] = limiter
+}
+
+// GetLimiter returns the limiter for a server name, nil if it has no limits
+func (r *ToolRegistry) GetLimiter(serverName string) *Limiter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return r.limiters[serverName]
+}
+
+// SetBreaker sets the circuit breaker shared by a server's tools
+func (r *ToolRegistry) SetBreaker(serverName string, breaker *CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.breakers[serverName] = breaker
+}
+
+// GetBreaker returns the circuit breaker for a server name, nil if disabled
+func (r *ToolRegistry) GetBreaker(serverName string) *CircuitBreaker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return r.breakers[serverName]
+}
+
+// SetChaos sets the fault injection for a server's tools
+func (r *ToolRegistry) SetChaos(serverName string, chaos *Chaos) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.chaos[serverName] = chaos
+}
+
+// GetChaos returns the fault injection for a server name, nil if it has none
+func (r *ToolRegistry) GetChaos(serverName string) *Chaos {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return r.chaos[serverName]
+}
+
+// SetCache sets the response cache used by the registry's handlers
+func (r *ToolRegistry) SetCache(cache *ResponseCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.cache = cache
+}
+
+// GetCache returns the response cache, nil if there is none
+func (r *ToolRegistry) GetCache() *ResponseCache {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return r.cache
+}
+
+// HandlerOptions returns the limiter, breaker, cache and fault injection for
+// a server's handlers
+func (r *ToolRegistry) HandlerOptions(serverName string) HandlerOptions {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
+	return HandlerOptions{
+		Limiter: r.limiters[serverName],
+		Breaker: r.breakers[serverName],
+		Cache:   r.cache,
+		Chaos:   r.chaos[serverName],
+	}
+}
+
 // GetAllTools returns all registered tools
 func (r *ToolRegistry) GetAllTools() []discovery.RemoteTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	
 	var tools []discovery.RemoteTool
 	for _, tool := range r.tools {
 		tools = append(tools, tool)
@@ -131,5 +431,5 @@ func (r *ToolRegistry) CreateHandlerForTool(prefixedToolName string) (server.Too
 	}
 	
 	// Create and return the handler
-	return CreateProxyHandler(mcpClient, tool), nil
+	return CreateProxyHandler(mcpClient, tool, r.HandlerOptions(tool.ServerName)), nil
 }
\ No newline at end of file
@@ -0,0 +1,48 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+
+	"mcp-debug/discovery"
+)
+
+func remoteTool(serverName, originalName, prefixedName string) discovery.RemoteTool {
+	return discovery.RemoteTool{ServerName: serverName, OriginalName: originalName, PrefixedName: prefixedName}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	r := NewToolRegistry()
+	r.ReserveName("server_add")
+	r.RegisterTool(remoteTool("db", "query", "db_query"), nil)
+
+	tests := []struct {
+		name       string
+		serverName string
+		tools      []discovery.RemoteTool
+		wantErr    string
+	}{
+		{"no conflicts", "fs", []discovery.RemoteTool{remoteTool("fs", "read", "fs_read")}, ""},
+		{"replacing own tools", "db", []discovery.RemoteTool{remoteTool("db", "query", "db_query")}, ""},
+		{"reserved name", "server", []discovery.RemoteTool{remoteTool("server", "add", "server_add")}, "built-in tool"},
+		{"another server's tool", "d", []discovery.RemoteTool{remoteTool("d", "b_query", "db_query")}, "server 'db'"},
+		{"same name twice", "fs", []discovery.RemoteTool{
+			remoteTool("fs", "read_file", "fs_read"),
+			remoteTool("fs", "read", "fs_read"),
+		}, "tools 'read_file' and 'read'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.CheckConflicts(tt.serverName, tt.tools)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckConflicts() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckConflicts() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
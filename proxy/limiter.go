@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"mcp-debug/config"
+)
+
+// Limiter enforces a server's concurrency and rate limits. All of a server's
+// tools share one limiter. Calls over a limit wait, for at most the server's
+// timeout; a nil limiter lets every call through.
+type Limiter struct {
+	slots   chan struct{} // one entry per running call, nil if unlimited
+	timeout time.Duration
+
+	// Token bucket, unused if rate is 0
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewLimiter creates a limiter from a server's configuration. Returns nil if
+// the server has no limits.
+func NewLimiter(cfg *config.ServerConfig) *Limiter {
+	if cfg.MaxConcurrent <= 0 && cfg.RateLimit == nil {
+		return nil
+	}
+
+	l := &Limiter{timeout: cfg.GetServerTimeout()}
+	if cfg.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	if cfg.RateLimit != nil {
+		l.rate = cfg.RateLimit.RequestsPerSecond
+		l.burst = float64(cfg.RateLimit.GetBurst())
+		l.tokens = l.burst
+		l.last = time.Now()
+	}
+	return l
+}
+
+// Acquire waits until a call may be forwarded and returns a function that
+// must be called once the call has finished
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	if err := l.waitForToken(waitCtx); err != nil {
+		return nil, l.waitError(ctx, "rate limit exceeded")
+	}
+
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-waitCtx.Done():
+		l.returnToken()
+		return nil, l.waitError(ctx, fmt.Sprintf("%d calls already running", cap(l.slots)))
+	}
+}
+
+// waitForToken takes a token from the bucket, waiting for one to be added
+// if it is empty
+func (l *Limiter) waitForToken(ctx context.Context) error {
+	if l.rate == 0 {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// returnToken puts back the token taken for a call that was not forwarded
+func (l *Limiter) returnToken() {
+	if l.rate == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.tokens+1, l.burst)
+}
+
+// waitError explains why a queued call was given up. A cancelled request is
+// reported as such rather than as a limit.
+func (l *Limiter) waitError(ctx context.Context, reason string) error {
+	if err := ctx.Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%s, call not forwarded within %v", reason, l.timeout)
+}
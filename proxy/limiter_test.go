@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"mcp-debug/config"
+)
+
+func TestNewLimiterWithoutLimits(t *testing.T) {
+	if l := NewLimiter(&config.ServerConfig{}); l != nil {
+		t.Fatalf("NewLimiter() = %+v, want nil", l)
+	}
+
+	var l *Limiter
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("nil Acquire() error = %v", err)
+	}
+	release()
+}
+
+func TestLimiterBurst(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    config.RateLimitConfig
+		allowed int
+	}{
+		{"default burst", config.RateLimitConfig{RequestsPerSecond: 2}, 2},
+		{"fractional rate", config.RateLimitConfig{RequestsPerSecond: 0.5}, 1},
+		{"explicit burst", config.RateLimitConfig{RequestsPerSecond: 1, Burst: 3}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := tt.rate
+			l := NewLimiter(&config.ServerConfig{RateLimit: &rate, Timeout: "20ms"})
+
+			for i := 0; i < tt.allowed; i++ {
+				release, err := l.Acquire(context.Background())
+				if err != nil {
+					t.Fatalf("call %d: Acquire() error = %v", i+1, err)
+				}
+				release()
+			}
+			_, err := l.Acquire(context.Background())
+			if err == nil || !strings.Contains(err.Error(), "rate limit exceeded") {
+				t.Fatalf("call %d: Acquire() error = %v, want rate limit exceeded", tt.allowed+1, err)
+			}
+		})
+	}
+}
+
+func TestLimiterRefills(t *testing.T) {
+	l := NewLimiter(&config.ServerConfig{
+		RateLimit: &config.RateLimitConfig{RequestsPerSecond: 50, Burst: 1},
+		Timeout:   "1s",
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := l.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("call %d: Acquire() error = %v", i+1, err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("3 calls at 50/s with burst 1 took %v, want at least 30ms", elapsed)
+	}
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	l := NewLimiter(&config.ServerConfig{MaxConcurrent: 1, Timeout: "20ms"})
+
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := l.Acquire(context.Background()); err == nil || !strings.Contains(err.Error(), "1 calls already running") {
+		t.Fatalf("second Acquire() error = %v, want calls already running", err)
+	}
+
+	release()
+	release, err = l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	release()
+}
+
+func TestLimiterSlotTimeoutKeepsToken(t *testing.T) {
+	l := NewLimiter(&config.ServerConfig{
+		MaxConcurrent: 1,
+		RateLimit:     &config.RateLimitConfig{RequestsPerSecond: 0.001, Burst: 2},
+		Timeout:       "20ms",
+	})
+
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := l.Acquire(context.Background()); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Fatalf("second Acquire() error = %v, want calls already running", err)
+	}
+	release()
+
+	// The timed-out call must not have used up the second token
+	release, err = l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() after slot timeout error = %v", err)
+	}
+	release()
+}
+
+func TestLimiterCancelled(t *testing.T) {
+	l := NewLimiter(&config.ServerConfig{MaxConcurrent: 1, Timeout: "1s"})
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire() error = %v, want context.Canceled", err)
+	}
+}
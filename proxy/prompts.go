@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"fmt"
+	"sync"
+
+	"mcp-debug/client"
+	"mcp-debug/discovery"
+)
+
+// PromptRegistry manages the mapping of prefixed prompts to their servers and clients
+type PromptRegistry struct {
+	prompts map[string]discovery.RemotePrompt // prefixed name -> prompt
+	clients map[string]client.MCPClient       // server name -> client
+	mu      sync.RWMutex
+}
+
+// NewPromptRegistry creates a new prompt registry
+func NewPromptRegistry() *PromptRegistry {
+	return &PromptRegistry{
+		prompts: make(map[string]discovery.RemotePrompt),
+		clients: make(map[string]client.MCPClient),
+	}
+}
+
+// SetServerPrompts replaces the prompts registered for a server and returns
+// the prefixed names of prompts that are no longer exposed. Nothing is
+// changed if a prompt name collides with another server's prompt.
+func (r *PromptRegistry) SetServerPrompts(serverName string, mcpClient client.MCPClient, prompts []discovery.RemotePrompt) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := make(map[string]bool, len(prompts))
+	for _, prompt := range prompts {
+		if existing, exists := r.prompts[prompt.PrefixedName]; exists && existing.ServerName != serverName {
+			return nil, fmt.Errorf("prompt '%s' from server '%s' and prompt '%s' from server '%s' are both exposed as '%s'",
+				prompt.OriginalName, serverName, existing.OriginalName, existing.ServerName, prompt.PrefixedName)
+		}
+		if current[prompt.PrefixedName] {
+			return nil, fmt.Errorf("server '%s' exposes more than one prompt as '%s'", serverName, prompt.PrefixedName)
+		}
+		current[prompt.PrefixedName] = true
+	}
+
+	var stale []string
+	for name, prompt := range r.prompts {
+		if prompt.ServerName == serverName && !current[name] {
+			stale = append(stale, name)
+			delete(r.prompts, name)
+		}
+	}
+
+	for _, prompt := range prompts {
+		r.prompts[prompt.PrefixedName] = prompt
+	}
+	r.clients[serverName] = mcpClient
+
+	return stale, nil
+}
+
+// RemoveServer removes all prompts for a server and returns their prefixed names
+func (r *PromptRegistry) RemoveServer(serverName string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var removed []string
+	for name, prompt := range r.prompts {
+		if prompt.ServerName == serverName {
+			removed = append(removed, name)
+			delete(r.prompts, name)
+		}
+	}
+	delete(r.clients, serverName)
+
+	return removed
+}
+
+// GetPrompt returns the prompt metadata and client for a prefixed prompt name
+func (r *PromptRegistry) GetPrompt(prefixedName string) (discovery.RemotePrompt, client.MCPClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prompt, exists := r.prompts[prefixedName]
+	if !exists {
+		return discovery.RemotePrompt{}, nil, false
+	}
+	return prompt, r.clients[prompt.ServerName], true
+}
+
+// PromptCount returns the number of prompts registered for a server
+func (r *PromptRegistry) PromptCount(serverName string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, prompt := range r.prompts {
+		if prompt.ServerName == serverName {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,227 @@
+package proxy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"mcp-debug/client"
+)
+
+// resourceURISeparator joins a server prefix to the original resource URI.
+// "file:///notes.md" from prefix "docs" becomes "docs+file:///notes.md",
+// which keeps the result a valid URI (the prefix extends the scheme).
+const resourceURISeparator = "+"
+
+// NamespaceURI returns the proxy-side URI (or URI template) for a resource
+func NamespaceURI(prefix, uri string) string {
+	return uriNamespace(prefix) + uri
+}
+
+// uriNamespace returns what NamespaceURI puts in front of a server's URIs
+func uriNamespace(prefix string) string {
+	return uriScheme(prefix) + resourceURISeparator
+}
+
+// uriScheme encodes a server prefix as the start of a URI scheme, which
+// RFC 3986 limits to a letter followed by letters, digits, "+", "-" and ".".
+// Letters, digits and "-" are kept and any other byte is written as "." and
+// two hex digits, so "my_db" becomes "my.5fdb". A prefix that does not start
+// with a letter gets "x.." in front, which the escapes never produce, so
+// distinct prefixes always give distinct schemes.
+func uriScheme(prefix string) string {
+	var b strings.Builder
+	if prefix == "" || !isASCIILetter(prefix[0]) {
+		b.WriteString("x..")
+	}
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		if isASCIILetter(c) || (c >= '0' && c <= '9') || c == '-' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, ".%02x", c)
+		}
+	}
+	return b.String()
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// serverResources holds the resources and templates exposed by one server
+type serverResources struct {
+	prefix    string
+	client    client.MCPClient
+	resources []client.ResourceInfo
+	templates []client.ResourceTemplateInfo
+}
+
+// ResourceRegistry aggregates resources from all connected servers and maps
+// proxy-side URIs back to the server that owns them
+type ResourceRegistry struct {
+	servers       map[string]*serverResources // server name -> resources
+	subscriptions map[string]bool             // proxy-side URIs the client subscribed to
+	mu            sync.RWMutex
+}
+
+// NewResourceRegistry creates a new resource registry
+func NewResourceRegistry() *ResourceRegistry {
+	return &ResourceRegistry{
+		servers:       make(map[string]*serverResources),
+		subscriptions: make(map[string]bool),
+	}
+}
+
+// SetServerResources replaces the resources and templates registered for a server
+func (r *ResourceRegistry) SetServerResources(serverName, prefix string, mcpClient client.MCPClient,
+	resources []client.ResourceInfo, templates []client.ResourceTemplateInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.servers[serverName] = &serverResources{
+		prefix:    prefix,
+		client:    mcpClient,
+		resources: resources,
+		templates: templates,
+	}
+}
+
+// RemoveServer removes all resources registered for a server. Returns true
+// if the server had any resources or templates.
+func (r *ResourceRegistry) RemoveServer(serverName string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.servers[serverName]
+	if !exists {
+		return false
+	}
+	delete(r.servers, serverName)
+	return len(entry.resources) > 0 || len(entry.templates) > 0
+}
+
+// ListResources returns the resources of all servers with proxy-side URIs
+func (r *ResourceRegistry) ListResources() []client.ResourceInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var resources []client.ResourceInfo
+	for _, serverName := range r.sortedServerNames() {
+		entry := r.servers[serverName]
+		for _, resource := range entry.resources {
+			resource.URI = NamespaceURI(entry.prefix, resource.URI)
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// ListResourceTemplates returns the templates of all servers with proxy-side URI templates
+func (r *ResourceRegistry) ListResourceTemplates() []client.ResourceTemplateInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var templates []client.ResourceTemplateInfo
+	for _, serverName := range r.sortedServerNames() {
+		entry := r.servers[serverName]
+		for _, template := range entry.templates {
+			template.URITemplate = NamespaceURI(entry.prefix, template.URITemplate)
+			templates = append(templates, template)
+		}
+	}
+	return templates
+}
+
+// Resolve maps a proxy-side URI to the owning server, its client and the
+// original URI. Encoded prefixes never contain the separator, so at most one
+// namespace matches; the longest match is taken all the same.
+func (r *ResourceRegistry) Resolve(uri string) (serverName string, mcpClient client.MCPClient, originalURI string, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bestLen := -1
+	for name, entry := range r.servers {
+		namespace := uriNamespace(entry.prefix)
+		if strings.HasPrefix(uri, namespace) && len(namespace) > bestLen {
+			bestLen = len(namespace)
+			serverName = name
+			mcpClient = entry.client
+			originalURI = strings.TrimPrefix(uri, namespace)
+		}
+	}
+
+	if bestLen < 0 {
+		return "", nil, "", fmt.Errorf("no server owns resource URI '%s'", uri)
+	}
+	return serverName, mcpClient, originalURI, nil
+}
+
+// ToProxyURI maps an original URI from a server to its proxy-side URI
+func (r *ResourceRegistry) ToProxyURI(serverName, uri string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, exists := r.servers[serverName]
+	if !exists {
+		return "", false
+	}
+	return NamespaceURI(entry.prefix, uri), true
+}
+
+// AddSubscription records a client subscription to a proxy-side URI
+func (r *ResourceRegistry) AddSubscription(uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscriptions[uri] = true
+}
+
+// RemoveSubscription removes a client subscription
+func (r *ResourceRegistry) RemoveSubscription(uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subscriptions, uri)
+}
+
+// ServerSubscriptions returns the original URIs the client is subscribed to
+// on a server, used to restore subscriptions after a reconnect
+func (r *ResourceRegistry) ServerSubscriptions(serverName string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, exists := r.servers[serverName]
+	if !exists {
+		return nil
+	}
+
+	namespace := uriNamespace(entry.prefix)
+	var uris []string
+	for uri := range r.subscriptions {
+		if strings.HasPrefix(uri, namespace) {
+			uris = append(uris, strings.TrimPrefix(uri, namespace))
+		}
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// ResourceCount returns the number of resources and templates for a server
+func (r *ResourceRegistry) ResourceCount(serverName string) (resources, templates int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if entry, exists := r.servers[serverName]; exists {
+		return len(entry.resources), len(entry.templates)
+	}
+	return 0, 0
+}
+
+func (r *ResourceRegistry) sortedServerNames() []string {
+	names := make([]string, 0, len(r.servers))
+	for name := range r.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"regexp"
+	"testing"
+
+	"mcp-debug/client"
+)
+
+// schemePattern is the RFC 3986 scheme syntax
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+
+func TestNamespaceURI(t *testing.T) {
+	tests := []struct {
+		prefix string
+		uri    string
+		want   string
+	}{
+		{"docs", "file:///notes.md", "docs+file:///notes.md"},
+		{"fs-2", "file:///a", "fs-2+file:///a"},
+		{"my_db", "db://t", "my.5fdb+db://t"},
+		{"2fs", "file:///a", "x..2fs+file:///a"},
+		{"_x", "file:///a", "x...5fx+file:///a"},
+		{"a.b", "file:///a", "a.2eb+file:///a"},
+		{"a+b", "file:///a", "a.2bb+file:///a"},
+		{"", "file:///a", "x..+file:///a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got := NamespaceURI(tt.prefix, tt.uri)
+			if got != tt.want {
+				t.Errorf("NamespaceURI(%q, %q) = %q, want %q", tt.prefix, tt.uri, got, tt.want)
+			}
+			if scheme := uriScheme(tt.prefix) + resourceURISeparator; tt.prefix != "" && !schemePattern.MatchString(scheme) {
+				t.Errorf("scheme %q for prefix %q is not a valid URI scheme", scheme, tt.prefix)
+			}
+		})
+	}
+}
+
+func TestURISchemeDistinct(t *testing.T) {
+	prefixes := []string{"x", "x.", ".x", "x..", "1", "x1", "x.31", "_", "x.5f", "a_b", "a.5fb", "a-b", "a+b"}
+	seen := make(map[string]string)
+	for _, prefix := range prefixes {
+		scheme := uriScheme(prefix)
+		if other, exists := seen[scheme]; exists {
+			t.Errorf("prefixes %q and %q both give scheme %q", other, prefix, scheme)
+		}
+		seen[scheme] = prefix
+	}
+}
+
+func TestResolveRoundTrip(t *testing.T) {
+	registry := NewResourceRegistry()
+	prefixes := map[string]string{
+		"docs":     "docs",
+		"files":    "my_fs",
+		"numbered": "2fs",
+		"nested":   "docs_2",
+	}
+	for serverName, prefix := range prefixes {
+		registry.SetServerResources(serverName, prefix, nil,
+			[]client.ResourceInfo{{URI: "file:///notes.md", Name: "notes"}}, nil)
+	}
+
+	for serverName := range prefixes {
+		proxyURI, ok := registry.ToProxyURI(serverName, "file:///notes.md")
+		if !ok {
+			t.Fatalf("ToProxyURI(%q) not found", serverName)
+		}
+		gotServer, _, gotURI, err := registry.Resolve(proxyURI)
+		if err != nil {
+			t.Fatalf("Resolve(%q) error = %v", proxyURI, err)
+		}
+		if gotServer != serverName || gotURI != "file:///notes.md" {
+			t.Errorf("Resolve(%q) = %q, %q, want %q, %q", proxyURI, gotServer, gotURI, serverName, "file:///notes.md")
+		}
+	}
+
+	if _, _, _, err := registry.Resolve("file:///notes.md"); err == nil {
+		t.Errorf("Resolve() of a URI without namespace succeeded")
+	}
+}
+
+func TestServerSubscriptions(t *testing.T) {
+	registry := NewResourceRegistry()
+	registry.SetServerResources("files", "my_fs", nil, nil, nil)
+	registry.SetServerResources("docs", "docs", nil, nil, nil)
+
+	registry.AddSubscription(NamespaceURI("my_fs", "file:///b"))
+	registry.AddSubscription(NamespaceURI("my_fs", "file:///a"))
+	registry.AddSubscription(NamespaceURI("docs", "file:///c"))
+
+	got := registry.ServerSubscriptions("files")
+	if len(got) != 2 || got[0] != "file:///a" || got[1] != "file:///b" {
+		t.Errorf("ServerSubscriptions() = %v, want [file:///a file:///b]", got)
+	}
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mcp-debug/config"
+)
+
+// defaultRedaction replaces redacted text when a rule has no replacement
+const defaultRedaction = "[REDACTED]"
+
+// ResponseTransformer applies configured transform rules to result text
+type ResponseTransformer struct {
+	redactions []redaction
+	prettyJSON bool
+	hasLines   bool
+	startLine  int
+	endLine    int // 0 means the last line
+	maxLength  int
+}
+
+type redaction struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// NewResponseTransformer compiles transform rules. Returns nil for nil rules;
+// a nil transformer leaves text unchanged.
+func NewResponseTransformer(rules *config.TransformRules) (*ResponseTransformer, error) {
+	if rules == nil {
+		return nil, nil
+	}
+
+	t := &ResponseTransformer{
+		prettyJSON: rules.PrettyJSON,
+		maxLength:  rules.MaxLength,
+	}
+
+	for _, rule := range rules.Redact {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern '%s': %w", rule.Pattern, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = defaultRedaction
+		}
+		t.redactions = append(t.redactions, redaction{pattern: pattern, replacement: replacement})
+	}
+
+	if rules.Lines != "" {
+		start, end, err := config.ParseLineRange(rules.Lines)
+		if err != nil {
+			return nil, err
+		}
+		t.hasLines, t.startLine, t.endLine = true, start, end
+	}
+
+	return t, nil
+}
+
+// Apply transforms result text
+func (t *ResponseTransformer) Apply(text string) string {
+	if t == nil {
+		return text
+	}
+
+	for _, r := range t.redactions {
+		text = r.pattern.ReplaceAllString(text, r.replacement)
+	}
+
+	if t.prettyJSON {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(text), "", "  "); err == nil {
+			text = indented.String()
+		}
+	}
+
+	if t.hasLines {
+		text = extractLines(text, t.startLine, t.endLine)
+	}
+
+	if t.maxLength > 0 {
+		text = truncate(text, t.maxLength)
+	}
+
+	return text
+}
+
+// extractLines returns lines start..end (1-based, inclusive, end 0 = last)
+func extractLines(text string, start, end int) string {
+	lines := strings.Split(text, "\n")
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if start > end {
+		return ""
+	}
+	return strings.Join(lines[start-1:end], "\n")
+}
+
+// truncate shortens text to maxLength characters and appends a marker
+// stating how much was cut
+func truncate(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return fmt.Sprintf("%s\n... [truncated %d of %d characters]",
+		string(runes[:maxLength]), len(runes)-maxLength, len(runes))
+}
@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+	"mcp-debug/discovery"
+	"mcp-debug/logging"
+	"mcp-debug/schema"
+)
+
+var validationLog = logging.For(logging.ComponentValidation)
+
+// Schemas checked by the proxy, as passed to a ViolationReporter
+const (
+	InputSchema  = "input"
+	OutputSchema = "output"
+)
+
+// ViolationReporter receives schema violations of calls that are passed on
+// anyway, e.g. to record them. schemaName is InputSchema or OutputSchema.
+type ViolationReporter func(tool discovery.RemoteTool, schemaName string, violations []schema.Violation)
+
+// CheckArguments validates a call's arguments against the tool's input
+// schema according to its validation mode. It returns an error result if the
+// call must be rejected. In warn mode violations are logged and passed to
+// report (which may be nil), and nil is returned.
+func CheckArguments(tool discovery.RemoteTool, args map[string]interface{}, report ViolationReporter) *mcp.CallToolResult {
+	if !validationEnabled(tool.InputValidation) {
+		return nil
+	}
+
+	violations, err := schema.Validate(tool.InputSchema, args)
+	if err != nil {
+		validationLog.Warn("Skipping argument validation", "server", tool.ServerName, "tool", tool.PrefixedName, "error", err)
+		return nil
+	}
+	return handleViolations(tool, InputSchema, tool.InputValidation, violations, report,
+		"Invalid arguments for %s (not forwarded)")
+}
+
+// CheckResult validates a successful result of a tool with an output schema:
+// structuredContent must be present and match the schema, and text content
+// holding JSON must agree with it. It returns an error result to send
+// instead of the result if the mode is enforce; in warn mode violations are
+// logged and reported, and nil is returned.
+func CheckResult(tool discovery.RemoteTool, result *client.CallToolResult, report ViolationReporter) *mcp.CallToolResult {
+	if !validationEnabled(tool.OutputValidation) || len(tool.OutputSchema) == 0 || result.IsError {
+		return nil
+	}
+
+	var violations []schema.Violation
+	if len(result.StructuredContent) == 0 || bytes.Equal(result.StructuredContent, []byte("null")) {
+		violations = append(violations, schema.Violation{
+			Keyword: "structuredContent",
+			Message: "tool declares an outputSchema but the result has no structuredContent",
+		})
+	} else {
+		var structured interface{}
+		if err := json.Unmarshal(result.StructuredContent, &structured); err != nil {
+			validationLog.Warn("Skipping output validation", "server", tool.ServerName, "tool", tool.PrefixedName, "error", err)
+			return nil
+		}
+
+		schemaViolations, err := schema.Validate(tool.OutputSchema, structured)
+		if err != nil {
+			validationLog.Warn("Skipping output validation", "server", tool.ServerName, "tool", tool.PrefixedName, "error", err)
+			return nil
+		}
+		violations = append(violations, schemaViolations...)
+
+		// Text content serializing the structured result must say the same
+		for i, content := range result.Content {
+			var text interface{}
+			if content.Type != "text" || json.Unmarshal([]byte(content.Text), &text) != nil {
+				continue
+			}
+			if !reflect.DeepEqual(text, structured) {
+				violations = append(violations, schema.Violation{
+					Path:    fmt.Sprintf("/content/%d", i),
+					Keyword: "content",
+					Message: "JSON text content does not match structuredContent",
+				})
+			}
+		}
+	}
+
+	return handleViolations(tool, OutputSchema, tool.OutputValidation, violations, report,
+		"Invalid result from %s")
+}
+
+func validationEnabled(mode string) bool {
+	return mode == config.ValidationWarn || mode == config.ValidationEnforce
+}
+
+// handleViolations turns violations into an error result in enforce mode,
+// or logs and reports them in warn mode
+func handleViolations(tool discovery.RemoteTool, schemaName, mode string, violations []schema.Violation, report ViolationReporter, rejectFormat string) *mcp.CallToolResult {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if mode == config.ValidationEnforce {
+		return mcp.NewToolResultError(fmt.Sprintf(rejectFormat+":\n%s", tool.PrefixedName, schema.Format(violations)))
+	}
+
+	validationLog.Warn("Schema violations (passed on)", "server", tool.ServerName, "tool", tool.PrefixedName,
+		"schema", schemaName, "violations", schema.Format(violations))
+	if report != nil {
+		report(tool, schemaName, violations)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-debug/config"
+)
+
+// ToolCaller calls a proxied tool by its exposed name
+type ToolCaller func(ctx context.Context, toolName string, args map[string]interface{}) (*mcp.CallToolResult, error)
+
+// templateRef matches {{ path }} references in virtual tool templates
+var templateRef = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// CreateVirtualTool creates the MCP tool definition for a virtual tool
+func CreateVirtualTool(def config.VirtualToolConfig) mcp.Tool {
+	properties := make(map[string]interface{}, len(def.Inputs))
+	required := make([]string, 0)
+	for _, input := range def.Inputs {
+		inputType := input.Type
+		if inputType == "" {
+			inputType = "string"
+		}
+		property := map[string]interface{}{"type": inputType}
+		if input.Description != "" {
+			property["description"] = input.Description
+		}
+		properties[input.Name] = property
+		if input.Required {
+			required = append(required, input.Name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	schemaBytes, _ := json.Marshal(schema)
+
+	description := def.Description
+	if description == "" {
+		steps := make([]string, 0, len(def.Steps))
+		for _, step := range def.Steps {
+			steps = append(steps, step.Tool)
+		}
+		description = "Runs " + strings.Join(steps, " -> ")
+	}
+
+	return mcp.NewToolWithRawSchema(def.Name, "[virtual] "+description, schemaBytes)
+}
+
+// CreateVirtualToolHandler creates a handler that runs the steps of a virtual
+// tool in order, stopping at the first step that fails
+func CreateVirtualToolHandler(def config.VirtualToolConfig, call ToolCaller) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		inputs := request.GetArguments()
+		for _, input := range def.Inputs {
+			if _, exists := inputs[input.Name]; input.Required && !exists {
+				return mcp.NewToolResultError(fmt.Sprintf("missing required input: %s", input.Name)), nil
+			}
+		}
+
+		steps := make(map[string]interface{}, len(def.Steps))
+		scope := map[string]interface{}{
+			"input": inputs,
+			"steps": steps,
+		}
+
+		var last map[string]interface{}
+		for i, step := range def.Steps {
+			id := step.StepID(i)
+
+			args, err := renderValue(step.Args, scope)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("step %s (%s): %v", id, step.Tool, err)), nil
+			}
+			argsMap, _ := args.(map[string]interface{})
+			if argsMap == nil {
+				argsMap = make(map[string]interface{})
+			}
+
+			result, err := call(ctx, step.Tool, argsMap)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("step %s (%s) failed: %v", id, step.Tool, err)), nil
+			}
+
+			last = stepResult(result)
+			steps[id] = last
+			if result.IsError {
+				return mcp.NewToolResultError(fmt.Sprintf("step %s (%s) failed: %s", id, step.Tool, last["text"])), nil
+			}
+		}
+
+		if def.Output == "" {
+			return mcp.NewToolResultText(last["text"].(string)), nil
+		}
+
+		output, err := renderValue(def.Output, scope)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("output: %v", err)), nil
+		}
+		if text, ok := output.(string); ok {
+			return mcp.NewToolResultText(text), nil
+		}
+		outputBytes, _ := json.Marshal(output)
+		return mcp.NewToolResultText(string(outputBytes)), nil
+	}
+}
+
+// stepResult exposes a tool result to templates as text, parsed JSON (when
+// the text is JSON) and the error flag
+func stepResult(result *mcp.CallToolResult) map[string]interface{} {
+	texts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok && text.Text != "" {
+			texts = append(texts, text.Text)
+		}
+	}
+	text := strings.Join(texts, "\n")
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		parsed = nil
+	}
+
+	return map[string]interface{}{
+		"text":    text,
+		"json":    parsed,
+		"isError": result.IsError,
+	}
+}
+
+// renderValue resolves template references in a value. A string made of a
+// single reference is replaced by the referenced value with its type kept,
+// so numbers and objects pass through; references embedded in longer
+// strings are interpolated as text.
+func renderValue(value interface{}, scope map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if match := templateRef.FindStringSubmatch(v); match != nil && match[0] == strings.TrimSpace(v) {
+			return lookupPath(scope, match[1])
+		}
+
+		var renderErr error
+		rendered := templateRef.ReplaceAllStringFunc(v, func(ref string) string {
+			resolved, err := lookupPath(scope, templateRef.FindStringSubmatch(ref)[1])
+			if err != nil {
+				renderErr = err
+				return ""
+			}
+			if text, ok := resolved.(string); ok {
+				return text
+			}
+			resolvedBytes, _ := json.Marshal(resolved)
+			return string(resolvedBytes)
+		})
+		return rendered, renderErr
+
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renderedItem, err := renderValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = renderedItem
+		}
+		return rendered, nil
+
+	case []interface{}:
+		rendered := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			renderedItem, err := renderValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, renderedItem)
+		}
+		return rendered, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// lookupPath resolves a dotted path such as steps.login.json.user.id, where
+// numeric segments index into arrays
+func lookupPath(scope map[string]interface{}, path string) (interface{}, error) {
+	var current interface{} = scope
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, exists := node[segment]
+			if !exists {
+				return nil, fmt.Errorf("unresolved reference '%s'", path)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("unresolved reference '%s'", path)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("unresolved reference '%s'", path)
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,290 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mcp-debug/config"
+)
+
+// Placeholder replaces redacted secrets
+const Placeholder = "[REDACTED]"
+
+// minValueLength keeps short config values such as DEBUG=1 from being
+// redacted everywhere they happen to appear
+const minValueLength = 3
+
+// detector matches a secret. If keep is set, the first capture group (such
+// as "Bearer " or "api_key=") is kept in front of the placeholder.
+type detector struct {
+	pattern *regexp.Regexp
+	keep    bool
+}
+
+// secretKeyNames are the keys whose values are secrets
+const secretKeyNames = `api[_-]?key|access[_-]?token|auth[_-]?token|token|secret|password|passwd`
+
+// secretKey matches a JSON object key named like secretKeyNames
+var secretKey = regexp.MustCompile(`(?i)^(?:` + secretKeyNames + `)$`)
+
+// builtinDetectors recognize common credential formats
+var builtinDetectors = []detector{
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`), true},
+	{regexp.MustCompile(`(?i)\b((?:` + secretKeyNames + `)["']?\s*[:=]\s*["']?)[^\s"'&,;}]{3,}`), true},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`), false},                                // OpenAI / Anthropic style keys
+	{regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}\b`), false},            // GitHub tokens
+	{regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}`), false},                         // GitHub fine-grained tokens
+	{regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), false},                         // Slack tokens
+	{regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), false},                                   // AWS access key IDs
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z\-_]{35}`), false},                               // Google API keys
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), false}, // JWTs
+}
+
+// sensitiveKey matches env variable names that hold secrets
+var sensitiveKey = regexp.MustCompile(`(?i)(key|token|secret|password|passwd|credential|auth)`)
+
+// Redactor removes secrets from text
+type Redactor struct {
+	detectors []detector
+	values    []string // literal secret values, longest first
+	disabled  bool
+	mu        sync.RWMutex
+}
+
+// Default is the redactor used for the proxy log and recordings
+var Default = New()
+
+// New creates a redactor with the built-in detectors
+func New() *Redactor {
+	return &Redactor{detectors: append([]detector(nil), builtinDetectors...)}
+}
+
+// Configure adds the user patterns and the env and auth values of the
+// configuration, or disables redaction if the config says so
+func (r *Redactor) Configure(cfg *config.ProxyConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.disabled = cfg.Redaction.Disabled
+
+	for _, pattern := range cfg.Redaction.Patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid redaction pattern '%s': %w", pattern, err)
+		}
+		r.detectors = append(r.detectors, detector{pattern: compiled})
+	}
+
+	secretEnv := make(map[string]bool, len(cfg.Redaction.Env))
+	for _, key := range cfg.Redaction.Env {
+		secretEnv[key] = true
+	}
+	for _, server := range cfg.Servers {
+		for key, value := range server.Env {
+			if sensitiveKey.MatchString(key) || secretEnv[key] {
+				r.addValue(value)
+			}
+		}
+		if server.Auth != nil {
+			r.addValue(server.Auth.Token)
+			r.addValue(server.Auth.Password)
+		}
+	}
+	return nil
+}
+
+// AddValue registers a literal secret value
+func (r *Redactor) AddValue(value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addValue(value)
+}
+
+func (r *Redactor) addValue(value string) {
+	if len(value) < minValueLength {
+		return
+	}
+
+	// Secrets also appear JSON-escaped inside recordings
+	variants := []string{value}
+	if escaped, err := json.Marshal(value); err == nil {
+		if inner := string(escaped[1 : len(escaped)-1]); inner != value {
+			variants = append(variants, inner)
+		}
+	}
+
+	for _, variant := range variants {
+		r.values = append(r.values, variant)
+	}
+
+	// Replace longer values first so a secret containing another is fully redacted
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+}
+
+// String returns text with all detected secrets replaced by the placeholder
+func (r *Redactor) String(text string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.disabled {
+		return text
+	}
+
+	for _, value := range r.values {
+		text = strings.ReplaceAll(text, value, Placeholder)
+	}
+	for _, d := range r.detectors {
+		if d.keep {
+			text = d.pattern.ReplaceAllString(text, "${1}"+Placeholder)
+		} else {
+			text = d.pattern.ReplaceAllString(text, Placeholder)
+		}
+	}
+	return text
+}
+
+// Bytes is String for byte slices
+func (r *Redactor) Bytes(data []byte) []byte {
+	return []byte(r.String(string(data)))
+}
+
+// JSON redacts a JSON document and keeps it valid: string values and keys
+// are redacted like text, and values of keys such as "token" or "password"
+// are replaced by the placeholder whatever their type. Key order and numbers
+// are kept as they are. Data that is not JSON is redacted as text.
+func (r *Redactor) JSON(data []byte) []byte {
+	r.mu.RLock()
+	disabled := r.disabled
+	r.mu.RUnlock()
+	if disabled {
+		return data
+	}
+
+	redacted, err := r.redactJSON(data)
+	if err != nil {
+		return r.Bytes(data)
+	}
+	if bytes.HasSuffix(data, []byte("\n")) {
+		redacted = append(redacted, '\n')
+	}
+	return redacted
+}
+
+// jsonContainer is an object or array being re-encoded by redactJSON
+type jsonContainer struct {
+	object bool
+	items  int    // keys and values written so far
+	key    string // last key of an object
+}
+
+func (r *Redactor) redactJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	var stack []*jsonContainer
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			out.WriteByte(byte(delim))
+			continue
+		}
+
+		// Write the separator and find the key this value belongs to
+		isKey, secret := false, false
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			switch {
+			case top.object && top.items%2 == 0:
+				isKey = true
+				if top.items > 0 {
+					out.WriteByte(',')
+				}
+				if key, ok := token.(string); ok {
+					top.key = key
+				}
+			case top.object:
+				out.WriteByte(':')
+				secret = secretKey.MatchString(top.key)
+			case top.items > 0:
+				out.WriteByte(',')
+			}
+			top.items++
+		}
+
+		switch value := token.(type) {
+		case json.Delim:
+			stack = append(stack, &jsonContainer{object: value == '{'})
+			out.WriteByte(byte(value))
+		case string:
+			if secret && !isKey && value != "" {
+				value = Placeholder
+			} else {
+				value = r.String(value)
+			}
+			writeJSONString(&out, value)
+		case json.Number, bool:
+			if secret {
+				writeJSONString(&out, Placeholder)
+			} else if number, ok := value.(json.Number); ok {
+				out.WriteString(number.String())
+			} else {
+				out.WriteString(strconv.FormatBool(value.(bool)))
+			}
+		case nil:
+			out.WriteString("null")
+		}
+	}
+	if len(stack) > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return out.Bytes(), nil
+}
+
+// writeJSONString writes s as a JSON string without escaping HTML characters
+func writeJSONString(out *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	out.Truncate(out.Len() - 1) // Encode adds a newline
+}
+
+// writer redacts everything written through it
+type writer struct {
+	w        io.Writer
+	redactor *Redactor
+}
+
+// NewWriter wraps w so that everything written is redacted first. Each Write
+// is redacted on its own, which suits the log package's one-write-per-entry
+// output.
+func NewWriter(w io.Writer, redactor *Redactor) io.Writer {
+	return &writer{w: w, redactor: redactor}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	redact := w.redactor.Bytes
+	if bytes.HasPrefix(p, []byte("{")) && json.Valid(p) {
+		// A JSON log entry
+		redact = w.redactor.JSON
+	}
+	if _, err := w.w.Write(redact(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
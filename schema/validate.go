@@ -0,0 +1,415 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation describes a value that does not satisfy a schema
+type Violation struct {
+	Path    string `json:"path"`    // JSON pointer to the failing value, "" for the root
+	Keyword string `json:"keyword"` // schema keyword that failed, e.g. "type" or "required"
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s (%s)", path, v.Message, v.Keyword)
+}
+
+// Validate checks a value against a JSON Schema. It supports the keywords
+// commonly found in tool schemas: type, enum, const, string, number, object
+// and array constraints, allOf/anyOf/oneOf/not, if/then/else and local $refs.
+// Unknown keywords, including format, are ignored. An empty schema accepts
+// everything.
+func Validate(schemaBytes json.RawMessage, value interface{}) ([]Violation, error) {
+	if len(schemaBytes) == 0 {
+		return nil, nil
+	}
+
+	var root interface{}
+	if err := json.Unmarshal(schemaBytes, &root); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	// Work on plain decoded JSON so numbers are float64 and objects are maps
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(valueBytes, &normalized); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	v := &validator{root: root}
+	v.validate(root, normalized, "")
+	return v.violations, nil
+}
+
+// Format lists violations one per line
+func Format(violations []Violation) string {
+	lines := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		lines = append(lines, "- "+violation.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type validator struct {
+	root       interface{}
+	violations []Violation
+	depth      int // guards against $ref cycles
+}
+
+// maxRefDepth bounds $ref resolution so recursive schemas cannot loop forever
+const maxRefDepth = 64
+
+func (v *validator) fail(path, keyword, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether a value satisfies a schema without recording violations
+func (v *validator) matches(schema, value interface{}, path string) bool {
+	sub := &validator{root: v.root, depth: v.depth}
+	sub.validate(schema, value, path)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) validate(schema, value interface{}, path string) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "false", "no value is allowed here")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObjectSchema(s, value, path)
+	}
+}
+
+func (v *validator) validateObjectSchema(s map[string]interface{}, value interface{}, path string) {
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "$ref", "%v", err)
+		} else if v.depth < maxRefDepth {
+			v.depth++
+			v.validate(target, value, path)
+			v.depth--
+		}
+	}
+
+	// A type mismatch makes the remaining keywords meaningless
+	if types, ok := s["type"]; ok && !matchesType(types, value) {
+		v.fail(path, "type", "expected %s, got %s", describeTypes(types), typeOf(value))
+		return
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			if reflect.DeepEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "enum", "must be one of %s, got %s", toJSON(enum), toJSON(value))
+		}
+	}
+	if constant, ok := s["const"]; ok && !reflect.DeepEqual(constant, value) {
+		v.fail(path, "const", "must be %s, got %s", toJSON(constant), toJSON(value))
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(s, val, path)
+	case float64:
+		v.validateNumber(s, val, path)
+	case map[string]interface{}:
+		v.validateObject(s, val, path)
+	case []interface{}:
+		v.validateArray(s, val, path)
+	}
+
+	v.validateCombinators(s, value, path)
+}
+
+func (v *validator) validateString(s map[string]interface{}, value, path string) {
+	length := utf8.RuneCountInString(value)
+	if min, ok := number(s["minLength"]); ok && float64(length) < min {
+		v.fail(path, "minLength", "must be at least %v characters, got %d", min, length)
+	}
+	if max, ok := number(s["maxLength"]); ok && float64(length) > max {
+		v.fail(path, "maxLength", "must be at most %v characters, got %d", max, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+			v.fail(path, "pattern", "must match %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(s map[string]interface{}, value float64, path string) {
+	if min, ok := number(s["minimum"]); ok {
+		// Draft 4 expresses exclusive bounds as a boolean next to minimum
+		if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive && value <= min {
+			v.fail(path, "exclusiveMinimum", "must be > %v, got %v", min, value)
+		} else if value < min {
+			v.fail(path, "minimum", "must be >= %v, got %v", min, value)
+		}
+	}
+	if max, ok := number(s["maximum"]); ok {
+		if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive && value >= max {
+			v.fail(path, "exclusiveMaximum", "must be < %v, got %v", max, value)
+		} else if value > max {
+			v.fail(path, "maximum", "must be <= %v, got %v", max, value)
+		}
+	}
+	if min, ok := number(s["exclusiveMinimum"]); ok && value <= min {
+		v.fail(path, "exclusiveMinimum", "must be > %v, got %v", min, value)
+	}
+	if max, ok := number(s["exclusiveMaximum"]); ok && value >= max {
+		v.fail(path, "exclusiveMaximum", "must be < %v, got %v", max, value)
+	}
+	if divisor, ok := number(s["multipleOf"]); ok && divisor > 0 {
+		if quotient := value / divisor; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.fail(path, "multipleOf", "must be a multiple of %v, got %v", divisor, value)
+		}
+	}
+}
+
+func (v *validator) validateObject(s map[string]interface{}, value map[string]interface{}, path string) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, exists := value[key]; !exists {
+					v.fail(childPath(path, key), "required", "required property is missing")
+				}
+			}
+		}
+	}
+	if min, ok := number(s["minProperties"]); ok && float64(len(value)) < min {
+		v.fail(path, "minProperties", "must have at least %v properties, got %d", min, len(value))
+	}
+	if max, ok := number(s["maxProperties"]); ok && float64(len(value)) > max {
+		v.fail(path, "maxProperties", "must have at most %v properties, got %d", max, len(value))
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	patternProperties, _ := s["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+
+	// Visit keys in order so violations are reported deterministically
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		propertyPath := childPath(path, key)
+		matched := false
+		if propertySchema, ok := properties[key]; ok {
+			matched = true
+			v.validate(propertySchema, value[key], propertyPath)
+		}
+		for pattern, propertySchema := range patternProperties {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+				matched = true
+				v.validate(propertySchema, value[key], propertyPath)
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok {
+			if !allowed {
+				v.fail(propertyPath, "additionalProperties", "unknown property is not allowed")
+			}
+		} else {
+			v.validate(additional, value[key], propertyPath)
+		}
+	}
+}
+
+func (v *validator) validateArray(s map[string]interface{}, value []interface{}, path string) {
+	if min, ok := number(s["minItems"]); ok && float64(len(value)) < min {
+		v.fail(path, "minItems", "must have at least %v items, got %d", min, len(value))
+	}
+	if max, ok := number(s["maxItems"]); ok && float64(len(value)) > max {
+		v.fail(path, "maxItems", "must have at most %v items, got %d", max, len(value))
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					v.fail(path, "uniqueItems", "items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+
+	// items is a schema for every item, or (before draft 2020-12) a list of
+	// schemas for the leading items
+	prefix, _ := s["prefixItems"].([]interface{})
+	items := s["items"]
+	if tuple, ok := items.([]interface{}); ok {
+		prefix, items = tuple, s["additionalItems"]
+	}
+	for i, item := range value {
+		itemPath := childPath(path, fmt.Sprint(i))
+		if i < len(prefix) {
+			v.validate(prefix[i], item, itemPath)
+		} else if items != nil {
+			v.validate(items, item, itemPath)
+		}
+	}
+}
+
+func (v *validator) validateCombinators(s map[string]interface{}, value interface{}, path string) {
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(sub, value, path)
+		}
+	}
+	if options, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range options {
+			if v.matches(sub, value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "anyOf", "does not match any of the allowed schemas")
+		}
+	}
+	if options, ok := s["oneOf"].([]interface{}); ok {
+		count := 0
+		for _, sub := range options {
+			if v.matches(sub, value, path) {
+				count++
+			}
+		}
+		if count != 1 {
+			v.fail(path, "oneOf", "must match exactly one schema, matched %d", count)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, value, path) {
+		v.fail(path, "not", "must not match the schema")
+	}
+	if condition, ok := s["if"]; ok {
+		if v.matches(condition, value, path) {
+			if then, ok := s["then"]; ok {
+				v.validate(then, value, path)
+			}
+		} else if otherwise, ok := s["else"]; ok {
+			v.validate(otherwise, value, path)
+		}
+	}
+}
+
+// resolve follows a local reference such as #/$defs/user
+func (v *validator) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported reference %q", ref)
+	}
+	current := v.root
+	for _, segment := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if segment == "" {
+			continue
+		}
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference %q", ref)
+		}
+		if current, ok = node[segment]; !ok {
+			return nil, fmt.Errorf("unresolved reference %q", ref)
+		}
+	}
+	return current, nil
+}
+
+func matchesType(types, value interface{}) bool {
+	switch t := types.(type) {
+	case string:
+		return isType(t, value)
+	case []interface{}:
+		for _, option := range t {
+			if name, ok := option.(string); ok && isType(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, value interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return typeOf(value) == name
+	}
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func describeTypes(types interface{}) string {
+	if list, ok := types.([]interface{}); ok {
+		names := make([]string, 0, len(list))
+		for _, name := range list {
+			names = append(names, fmt.Sprint(name))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func number(value interface{}) (float64, bool) {
+	n, ok := value.(float64)
+	return n, ok
+}
+
+func toJSON(value interface{}) string {
+	valueBytes, _ := json.Marshal(value)
+	return string(valueBytes)
+}
+
+// childPath appends a key to a JSON pointer
+func childPath(path, key string) string {
+	return path + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
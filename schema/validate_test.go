@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []string // "path keyword" of each violation
+	}{
+		{"empty schema", ``, `{"a":1}`, nil},
+		{"true schema", `true`, `1`, nil},
+		{"false schema", `false`, `1`, []string{" false"}},
+
+		{"type", `{"type":"string"}`, `1`, []string{" type"}},
+		{"type list", `{"type":["string","null"]}`, `null`, nil},
+		{"integer", `{"type":"integer"}`, `1.5`, []string{" type"}},
+		{"integer as float", `{"type":"integer"}`, `2.0`, nil},
+		{"type mismatch skips other keywords", `{"type":"string","minLength":5}`, `1`, []string{" type"}},
+		{"enum", `{"enum":["a","b"]}`, `"c"`, []string{" enum"}},
+		{"const", `{"const":{"x":1}}`, `{"x":1}`, nil},
+
+		{"string length", `{"minLength":2,"maxLength":3}`, `"é"`, []string{" minLength"}},
+		{"string max length", `{"maxLength":3}`, `"abcd"`, []string{" maxLength"}},
+		{"pattern", `{"pattern":"^[a-z]+$"}`, `"abc1"`, []string{" pattern"}},
+		{"invalid pattern ignored", `{"pattern":"("}`, `"x"`, nil},
+
+		{"minimum", `{"minimum":1,"maximum":3}`, `0`, []string{" minimum"}},
+		{"maximum", `{"maximum":3}`, `4`, []string{" maximum"}},
+		{"draft 4 exclusive minimum", `{"minimum":1,"exclusiveMinimum":true}`, `1`, []string{" exclusiveMinimum"}},
+		{"exclusive maximum", `{"exclusiveMaximum":3}`, `3`, []string{" exclusiveMaximum"}},
+		{"multipleOf", `{"multipleOf":0.1}`, `0.3`, nil},
+		{"not a multiple", `{"multipleOf":2}`, `3`, []string{" multipleOf"}},
+
+		{"required", `{"required":["a","b"]}`, `{"a":1}`, []string{"/b required"}},
+		{"nested property", `{"properties":{"a":{"properties":{"b":{"type":"number"}}}}}`, `{"a":{"b":"x"}}`, []string{"/a/b type"}},
+		{"escaped path", `{"properties":{"a/b~c":{"type":"number"}}}`, `{"a/b~c":"x"}`, []string{"/a~1b~0c type"}},
+		{"additional properties", `{"properties":{"a":{}},"additionalProperties":false}`, `{"a":1,"c":2,"b":3}`,
+			[]string{"/b additionalProperties", "/c additionalProperties"}},
+		{"additional properties schema", `{"additionalProperties":{"type":"string"}}`, `{"a":1}`, []string{"/a type"}},
+		{"pattern properties", `{"patternProperties":{"^x_":{"type":"number"}},"additionalProperties":false}`, `{"x_a":"s"}`,
+			[]string{"/x_a type"}},
+		{"property count", `{"minProperties":2}`, `{"a":1}`, []string{" minProperties"}},
+
+		{"items", `{"items":{"type":"number"}}`, `[1,"x"]`, []string{"/1 type"}},
+		{"item count", `{"minItems":1,"maxItems":2}`, `[]`, []string{" minItems"}},
+		{"unique items", `{"uniqueItems":true}`, `[{"a":1},{"a":1}]`, []string{" uniqueItems"}},
+		{"prefix items", `{"prefixItems":[{"type":"string"}],"items":{"type":"number"}}`, `["a",1,"b"]`, []string{"/2 type"}},
+		{"tuple items", `{"items":[{"type":"string"}],"additionalItems":false}`, `["a",1]`, []string{"/1 false"}},
+
+		{"allOf", `{"allOf":[{"minimum":1},{"maximum":2}]}`, `3`, []string{" maximum"}},
+		{"anyOf", `{"anyOf":[{"type":"string"},{"type":"number"}]}`, `true`, []string{" anyOf"}},
+		{"oneOf matching both", `{"oneOf":[{"minimum":1},{"maximum":5}]}`, `3`, []string{" oneOf"}},
+		{"oneOf", `{"oneOf":[{"minimum":1},{"maximum":0}]}`, `3`, nil},
+		{"not", `{"not":{"type":"null"}}`, `null`, []string{" not"}},
+		{"if then", `{"if":{"properties":{"k":{"const":"a"}}},"then":{"required":["a"]},"else":{"required":["b"]}}`,
+			`{"k":"a"}`, []string{"/a required"}},
+		{"if else", `{"if":{"properties":{"k":{"const":"a"}}},"then":{"required":["a"]},"else":{"required":["b"]}}`,
+			`{"k":"z"}`, []string{"/b required"}},
+
+		{"ref", `{"$defs":{"id":{"type":"integer"}},"properties":{"id":{"$ref":"#/$defs/id"}}}`, `{"id":"x"}`, []string{"/id type"}},
+		{"unresolved ref", `{"$ref":"#/$defs/missing"}`, `1`, []string{" $ref"}},
+		{"remote ref", `{"$ref":"http://example.com/schema"}`, `1`, []string{" $ref"}},
+		{"recursive ref", `{"$ref":"#"}`, `1`, nil},
+		{"recursive tree", `{"properties":{"children":{"items":{"$ref":"#"}},"name":{"type":"string"}}}`,
+			`{"children":[{"name":1}]}`, []string{"/children/0/name type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatalf("invalid test value: %v", err)
+			}
+			violations, err := Validate(json.RawMessage(tt.schema), value)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			var got []string
+			for _, violation := range violations {
+				got = append(got, violation.Path+" "+violation.Keyword)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q\n%s", got, tt.want, Format(violations))
+			}
+		})
+	}
+}
+
+func TestValidateInvalidSchema(t *testing.T) {
+	if _, err := Validate(json.RawMessage(`{`), 1); err == nil {
+		t.Errorf("Validate() with an invalid schema succeeded")
+	}
+}
+
+func TestValidateNormalizesValues(t *testing.T) {
+	// Go values are checked as the JSON they encode to
+	value := map[string]interface{}{"count": 3, "tags": []string{"a"}}
+	violations, err := Validate(json.RawMessage(`{"properties":{"count":{"type":"integer"},"tags":{"items":{"type":"string"}}}}`), value)
+	if err != nil || len(violations) > 0 {
+		t.Errorf("Validate() = %v, %v, want no violations", violations, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	got := Format([]Violation{
+		{Path: "", Keyword: "type", Message: "expected object, got array"},
+		{Path: "/a", Keyword: "required", Message: "required property is missing"},
+	})
+	want := strings.Join([]string{
+		"- /: expected object, got array (type)",
+		"- /a: required property is missing (required)",
+	}, "\n")
+	if got != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// docs holds the documents served as resources
+var docs = map[string]string{
+	"readme":    "# Docs Server\n\nServes documents as MCP resources.",
+	"changelog": "## 1.0.0\n\n- Initial release",
+}
+
+func main() {
+	// Create MCP server with resources alongside a tool
+	s := server.NewMCPServer(
+		"Docs Server",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+	)
+
+	// Add a static resource for each document
+	for name, content := range docs {
+		text := content
+		resource := mcp.NewResource(
+			fmt.Sprintf("docs://%s", name),
+			name,
+			mcp.WithResourceDescription(fmt.Sprintf("The %s document", name)),
+			mcp.WithMIMEType("text/markdown"),
+		)
+		s.AddResource(resource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "text/markdown", Text: text},
+			}, nil
+		})
+	}
+
+	// Add a template resolving any document by name
+	template := mcp.NewResourceTemplate(
+		"docs://by-name/{name}",
+		"Document by name",
+		mcp.WithTemplateDescription("Look up a document by its name"),
+		mcp.WithTemplateMIMEType("text/markdown"),
+	)
+	s.AddResourceTemplate(template, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		name := strings.TrimPrefix(request.Params.URI, "docs://by-name/")
+		content, exists := docs[name]
+		if !exists {
+			return nil, fmt.Errorf("document not found: %s", name)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "text/markdown", Text: content},
+		}, nil
+	})
+
+	// Add a tool that creates documents, which changes the resource list
+	addTool := mcp.NewTool("add_doc",
+		mcp.WithDescription("Add a document"),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Document name")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Document content")),
+	)
+	s.AddTool(addTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := request.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		content, err := request.RequireString("content")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		docs[name] = content
+		s.AddResource(mcp.NewResource(fmt.Sprintf("docs://%s", name), name, mcp.WithMIMEType("text/markdown")),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "text/markdown", Text: content},
+				}, nil
+			})
+		return mcp.NewToolResultText(fmt.Sprintf("Added document %s", name)), nil
+	})
+
+	// Add a prompt for summarizing a document
+	summarizePrompt := mcp.NewPrompt("summarize",
+		mcp.WithPromptDescription("Summarize a document"),
+		mcp.WithArgument("name", mcp.RequiredArgument(), mcp.ArgumentDescription("Document name")),
+		mcp.WithArgument("style", mcp.ArgumentDescription("Summary style, e.g. brief or detailed")),
+	)
+	s.AddPrompt(summarizePrompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		name := request.Params.Arguments["name"]
+		content, exists := docs[name]
+		if !exists {
+			return nil, fmt.Errorf("document not found: %s", name)
+		}
+		style := request.Params.Arguments["style"]
+		if style == "" {
+			style = "brief"
+		}
+		return mcp.NewGetPromptResult(
+			fmt.Sprintf("Summarize %s", name),
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(fmt.Sprintf("Write a %s summary of:\n\n%s", style, content))),
+			},
+		), nil
+	})
+
+	// Start stdio server
+	if err := server.ServeStdio(s); err != nil {
+		fmt.Fprintf(os.Stderr, "Docs server error: %v\n", err)
+		os.Exit(1)
+	}
+}
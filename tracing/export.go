@@ -0,0 +1,242 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp-debug/config"
+	"mcp-debug/logging"
+)
+
+var exportLog = logging.For(logging.ComponentTracing)
+
+const (
+	queueSize     = 2048            // spans waiting for export; more are dropped
+	batchSize     = 256             // spans per export request
+	flushInterval = 2 * time.Second // longest a span waits for export
+	exportTimeout = 10 * time.Second
+	closeTimeout  = 5 * time.Second
+)
+
+// exporter sends ended spans in batches to an OTLP/HTTP endpoint and appends
+// them to a file, both in the OTLP JSON encoding
+type exporter struct {
+	url         string // OTLP traces URL, empty if not exporting over HTTP
+	file        *os.File
+	serviceName string
+	httpClient  *http.Client
+	queue       chan *Span
+	done        chan struct{}
+	closed      bool
+	mu          sync.Mutex
+}
+
+func newExporter(cfg config.TracingConfig) (*exporter, error) {
+	e := &exporter{
+		serviceName: cfg.GetServiceName(),
+		httpClient:  &http.Client{Timeout: exportTimeout},
+		queue:       make(chan *Span, queueSize),
+		done:        make(chan struct{}),
+	}
+
+	if cfg.Endpoint != "" {
+		e.url = strings.TrimSuffix(cfg.Endpoint, "/")
+		if !strings.HasSuffix(e.url, "/v1/traces") {
+			e.url += "/v1/traces"
+		}
+	}
+
+	if cfg.File != "" {
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		e.file = file
+	}
+
+	go e.run()
+	return e, nil
+}
+
+// export queues an ended span, dropping it if the queue is full or the
+// exporter is closed
+func (e *exporter) export(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+
+	select {
+	case e.queue <- span:
+	default:
+		exportLog.Warn("Trace export queue full, dropping span", "span", span.name)
+	}
+}
+
+// close exports the queued spans and closes the file. Closing again does
+// nothing.
+func (e *exporter) close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.queue)
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+	case <-time.After(closeTimeout):
+		return fmt.Errorf("timed out exporting remaining spans")
+	}
+	if e.file != nil {
+		return e.file.Close()
+	}
+	return nil
+}
+
+// run batches queued spans until the queue is closed
+func (e *exporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	for {
+		select {
+		case span, ok := <-e.queue:
+			if !ok {
+				e.flush(batch)
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) >= batchSize {
+				e.flush(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			e.flush(batch)
+			batch = nil
+		}
+	}
+}
+
+// flush writes a batch of spans to the file and the endpoint
+func (e *exporter) flush(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(e.exportRequest(batch))
+	if err != nil {
+		exportLog.Error("Failed to encode spans", "error", err)
+		return
+	}
+
+	if e.file != nil {
+		if _, err := e.file.Write(append(data, '\n')); err != nil {
+			exportLog.Error("Failed to write spans", "error", err)
+		}
+	}
+
+	if e.url != "" {
+		response, err := e.httpClient.Post(e.url, "application/json", bytes.NewReader(data))
+		if err != nil {
+			exportLog.Error("Failed to export spans", "spans", len(batch), "error", err)
+			return
+		}
+		response.Body.Close()
+		if response.StatusCode/100 != 2 {
+			exportLog.Error("Failed to export spans", "spans", len(batch), "status", response.Status)
+		}
+	}
+}
+
+// exportRequest builds an OTLP ExportTraceServiceRequest in its JSON encoding
+func (e *exporter) exportRequest(batch []*Span) map[string]interface{} {
+	spans := make([]map[string]interface{}, 0, len(batch))
+	for _, span := range batch {
+		spans = append(spans, span.otlp())
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{"service.name": e.serviceName}),
+			},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": "mcp-debug"},
+				"spans": spans,
+			}},
+		}},
+	}
+}
+
+// otlp encodes an ended span for OTLP JSON
+func (s *Span) otlp() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoded := map[string]interface{}{
+		"traceId":           hex.EncodeToString(s.traceID[:]),
+		"spanId":            hex.EncodeToString(s.spanID[:]),
+		"name":              s.name,
+		"kind":              int(s.kind),
+		"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+		"attributes":        otlpAttributes(s.attributes),
+	}
+	if s.parentID != ([8]byte{}) {
+		encoded["parentSpanId"] = hex.EncodeToString(s.parentID[:])
+	}
+
+	// Status codes: 1 is OK, 2 is error
+	status := map[string]interface{}{"code": 1}
+	if s.failed {
+		status = map[string]interface{}{"code": 2, "message": s.message}
+	}
+	encoded["status"] = status
+	return encoded
+}
+
+// otlpAttributes encodes attributes as OTLP key-value pairs, sorted by key
+func otlpAttributes(attributes map[string]interface{}) []map[string]interface{} {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	encoded := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		var value map[string]interface{}
+		switch v := attributes[key].(type) {
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, map[string]interface{}{"key": key, "value": value})
+	}
+	return encoded
+}
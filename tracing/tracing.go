@@ -0,0 +1,275 @@
+// Package tracing records OpenTelemetry spans for tool calls handled by the
+// proxy and propagates W3C trace context to child servers.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mcp-debug/client"
+	"mcp-debug/config"
+	"mcp-debug/redact"
+)
+
+// SpanKind is the OTLP span kind
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// Result statuses, the mcp.result.status attribute
+const (
+	StatusOK        = "ok"
+	StatusToolError = "tool_error" // result with isError set
+	StatusError     = "error"      // the call failed
+)
+
+// maxStatusMessage limits how much of an error is kept in a span's status
+const maxStatusMessage = 256
+
+// traceparentPattern matches a W3C traceparent header of version 00
+var traceparentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// Tracer creates spans and exports them when they end. A nil Tracer records
+// nothing.
+type Tracer struct {
+	exporter *exporter
+}
+
+// New creates a tracer exporting to the configured endpoint and file
+func New(cfg config.TracingConfig) (*Tracer, error) {
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Tracer{exporter: exporter}, nil
+}
+
+// Close exports the spans still buffered. It is safe to call more than once.
+func (t *Tracer) Close() error {
+	if t == nil {
+		return nil
+	}
+	return t.exporter.close()
+}
+
+// Span is a timed operation within a trace. A nil Span records nothing.
+type Span struct {
+	tracer     *Tracer
+	name       string
+	kind       SpanKind
+	traceID    [16]byte
+	spanID     [8]byte
+	parentID   [8]byte
+	sampled    bool
+	start      time.Time
+	end        time.Time
+	attributes map[string]interface{}
+	failed     bool
+	message    string
+	mu         sync.Mutex
+}
+
+// spanKey is the context key of the current span
+type spanKey struct{}
+
+// FromContext returns the current span, nil if there is none
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start starts a child of the current span. Without a current span it
+// returns ctx and a nil span.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	span := parent.tracer.newSpan(name, kind)
+	span.traceID = parent.traceID
+	span.parentID = parent.spanID
+	span.sampled = parent.sampled
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *Tracer) newSpan(name string, kind SpanKind) *Span {
+	span := &Span{
+		tracer:     t,
+		name:       name,
+		kind:       kind,
+		sampled:    true,
+		start:      time.Now(),
+		attributes: make(map[string]interface{}),
+	}
+	rand.Read(span.spanID[:])
+	return span
+}
+
+// startRemote starts a span continuing the trace of a traceparent header,
+// or a new trace if the header is missing or invalid
+func (t *Tracer) startRemote(ctx context.Context, name string, kind SpanKind, traceparent string) (context.Context, *Span) {
+	span := t.newSpan(name, kind)
+	if match := traceparentPattern.FindStringSubmatch(traceparent); match != nil {
+		hex.Decode(span.traceID[:], []byte(match[1]))
+		hex.Decode(span.parentID[:], []byte(match[2]))
+		flags, _ := hex.DecodeString(match[3])
+		span.sampled = flags[0]&1 == 1
+	}
+	if span.traceID == ([16]byte{}) {
+		rand.Read(span.traceID[:])
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttribute sets an attribute of the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// SetError marks the span as failed, with secrets redacted from the message
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	message = redact.Default.String(message)
+	if len(message) > maxStatusMessage {
+		message = strings.ToValidUTF8(message[:maxStatusMessage], "")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = true
+	s.message = message
+}
+
+// End ends the span and hands it to the exporter. Only the first call counts.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.sampled {
+		s.tracer.exporter.export(s)
+	}
+}
+
+// Traceparent returns the span's W3C traceparent header
+func (s *Span) Traceparent() string {
+	flags := "00"
+	if s.sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%x-%x-%s", s.traceID, s.spanID, flags)
+}
+
+// Instrument wraps a tool handler in a server span for the client's request,
+// continuing the client's trace if its _meta carries a traceparent. Returns
+// the handler unchanged for a nil tracer.
+func (t *Tracer) Instrument(serverName, toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if t == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		traceparent := ""
+		if meta := request.Params.Meta; meta != nil {
+			traceparent, _ = meta.AdditionalFields["traceparent"].(string)
+		}
+
+		ctx, span := t.startRemote(ctx, "tools/call "+toolName, SpanKindServer, traceparent)
+		defer span.End()
+		span.SetAttribute("mcp.server", serverName)
+		span.SetAttribute("mcp.tool", toolName)
+		span.SetAttribute("mcp.arguments.size", argumentSize(request.GetArguments()))
+
+		result, err := handler(ctx, request)
+		switch {
+		case err != nil:
+			span.SetAttribute("mcp.result.status", StatusError)
+			span.SetError(err.Error())
+		case result != nil && result.IsError:
+			span.SetAttribute("mcp.result.status", StatusToolError)
+			span.SetError(resultText(result))
+		default:
+			span.SetAttribute("mcp.result.status", StatusOK)
+		}
+		return result, err
+	}
+}
+
+// StartCall starts a client span for forwarding a tool call to a server, and
+// returns a context that sends the span's trace context in the call's _meta
+func StartCall(ctx context.Context, serverName, toolName string, args map[string]interface{}) (context.Context, *Span) {
+	ctx, span := Start(ctx, "tools/call "+toolName, SpanKindClient)
+	if span == nil {
+		return ctx, nil
+	}
+	span.SetAttribute("mcp.server", serverName)
+	span.SetAttribute("mcp.tool", toolName)
+	span.SetAttribute("mcp.arguments.size", argumentSize(args))
+	return client.WithMeta(ctx, map[string]interface{}{"traceparent": span.Traceparent()}), span
+}
+
+// EndCall records the outcome of a forwarded call and ends its span
+func (s *Span) EndCall(result *client.CallToolResult, err error) {
+	if s == nil {
+		return
+	}
+	switch {
+	case err != nil:
+		s.SetAttribute("mcp.result.status", StatusError)
+		s.SetError(err.Error())
+	case result.IsError:
+		s.SetAttribute("mcp.result.status", StatusToolError)
+		if len(result.Content) > 0 {
+			s.SetError(result.Content[0].Text)
+		}
+	default:
+		s.SetAttribute("mcp.result.status", StatusOK)
+	}
+	s.End()
+}
+
+// argumentSize returns the size of the arguments as JSON
+func argumentSize(args map[string]interface{}) int {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// resultText returns the first text content of a result
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}